@@ -1,9 +1,9 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 )
 
 type AirQualityData struct {
@@ -11,13 +11,13 @@ type AirQualityData struct {
 	PM25 float64 `json:"pm25"`
 }
 
-func fetchAirQuality(lat, lon float64) (*AirQualityData, error) {
+func fetchAirQuality(ctx context.Context, lat, lon float64) (*AirQualityData, error) {
 	url := fmt.Sprintf(
 		"https://air-quality-api.open-meteo.com/v1/air-quality?latitude=%f&longitude=%f&current=pm10,pm2_5&timezone=Asia/Seoul",
 		lat, lon,
 	)
 
-	resp, err := http.Get(url)
+	resp, err := httpGet(ctx, url, nil)
 	if err != nil {
 		return nil, err
 	}
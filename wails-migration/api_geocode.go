@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,20 +9,17 @@ import (
 	"strconv"
 )
 
-func geocodeAddress(address string) (*Coords, error) {
+func geocodeAddress(ctx context.Context, address string) (*Coords, error) {
 	u := fmt.Sprintf(
 		"https://nominatim.openstreetmap.org/search?q=%s&format=json&limit=1&countrycodes=kr",
 		url.QueryEscape(address),
 	)
 
-	req, err := http.NewRequest("GET", u, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Accept-Language", "ko")
-	req.Header.Set("User-Agent", "Wall-E-SchoolDashboard/1.0")
+	header := http.Header{}
+	header.Set("Accept-Language", "ko")
+	header.Set("User-Agent", "Wall-E-SchoolDashboard/1.0")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := httpGet(ctx, u, header)
 	if err != nil {
 		return nil, err
 	}
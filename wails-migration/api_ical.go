@@ -0,0 +1,660 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ===== iCalendar (.ics) import =====
+// Google Calendar, Outlook and Naver Calendar all export RFC 5545 files.
+// Each configured source is either an http(s)/webcal URL or a local file path.
+
+// icsEvent is a single VEVENT as read from the file, before recurrence expansion.
+type icsEvent struct {
+	UID          string
+	Summary      string
+	Description  string
+	Start        time.Time
+	End          time.Time // exclusive, as in DTEND
+	AllDay       bool
+	RRule        map[string]string
+	ExDates      map[string]bool // YYYYMMDD
+	RecurrenceID string          // YYYYMMDD of the overridden instance
+	Cancelled    bool
+}
+
+// fetchICSEvents reads every source and returns the occurrences in
+// [fromDate, toDate]. notes lists unsupported rules not reported before, for
+// the caller to log; sources are named by host or file name only, since
+// private calendar URLs carry their access token in the path.
+func fetchICSEvents(ctx context.Context, sources []string, fromDate, toDate string) (events []ScheduleEvent, notes []string, err error) {
+	from, err := time.ParseInLocation("20060102", fromDate, time.Local)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := time.ParseInLocation("20060102", toDate, time.Local)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs []string
+	for _, src := range sources {
+		src = strings.TrimSpace(src)
+		if src == "" {
+			continue
+		}
+		text, err := readICSSource(ctx, src)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		parsed := parseICS(text)
+		for _, ev := range parsed {
+			if reason := icsUnsupportedRule(ev); reason != "" {
+				if note, ok := icsRuleNote(src, ev, reason); ok {
+					notes = append(notes, note)
+				}
+			}
+		}
+		events = append(events, icsToEvents(parsed, from, to)...)
+	}
+
+	if len(errs) > 0 {
+		return events, notes, fmt.Errorf("캘린더 불러오기 오류: %s", strings.Join(errs, "; "))
+	}
+	return events, notes, nil
+}
+
+// icsSourceName names a source without secrets: the host of a URL, or the
+// file name of a local path.
+func icsSourceName(src string) string {
+	if u, err := url.Parse(src); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return filepath.Base(strings.TrimPrefix(src, "file://"))
+}
+
+func readICSSource(ctx context.Context, src string) (string, error) {
+	lower := strings.ToLower(src)
+	if strings.HasPrefix(lower, "webcal://") {
+		src = "https://" + src[len("webcal://"):]
+		lower = strings.ToLower(src)
+	}
+
+	if strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+		resp, err := httpGet(ctx, src, nil)
+		if err != nil {
+			var urlErr *url.Error
+			if errors.As(err, &urlErr) {
+				urlErr.URL = icsSourceName(src)
+			}
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != 200 {
+			return "", fmt.Errorf("calendar %s returned %d", icsSourceName(src), resp.StatusCode)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		return string(body), nil
+	}
+
+	data, err := os.ReadFile(strings.TrimPrefix(src, "file://"))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// unfoldICSLines joins RFC 5545 folded lines (continuations start with a space or tab).
+func unfoldICSLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, "\r"))
+	}
+	return lines
+}
+
+// splitICSProperty splits "DTSTART;TZID=Asia/Seoul:20260302T090000" into
+// its name, parameters and value.
+func splitICSProperty(line string) (string, map[string]string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return "", nil, ""
+	}
+	head, value := line[:colon], line[colon+1:]
+
+	parts := strings.Split(head, ";")
+	params := make(map[string]string)
+	for _, p := range parts[1:] {
+		if eq := strings.Index(p, "="); eq >= 0 {
+			params[strings.ToUpper(p[:eq])] = strings.Trim(p[eq+1:], `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, value
+}
+
+func unescapeICSText(s string) string {
+	r := strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+	return strings.TrimSpace(r.Replace(s))
+}
+
+// parseICSTime parses a DATE or DATE-TIME value. UTC values ("Z" suffix) and
+// TZID values are converted to local time; floating times are taken as local.
+func parseICSTime(value string, params map[string]string) (time.Time, bool, error) {
+	if params["VALUE"] == "DATE" || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		return t.In(time.Local), false, err
+	}
+
+	loc := time.Local
+	if tzid := params["TZID"]; tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	return t.In(time.Local), false, err
+}
+
+// parseICSDuration supports the day/week/hour/minute forms calendar apps emit
+// (e.g. "P1D", "P2W", "PT1H30M").
+func parseICSDuration(value string) time.Duration {
+	value = strings.TrimPrefix(strings.TrimPrefix(value, "+"), "P")
+	var d time.Duration
+	inTime := false
+	num := ""
+	for _, ch := range value {
+		switch {
+		case ch >= '0' && ch <= '9':
+			num += string(ch)
+		case ch == 'T':
+			inTime = true
+		default:
+			n, _ := strconv.Atoi(num)
+			num = ""
+			switch {
+			case ch == 'W':
+				d += time.Duration(n) * 7 * 24 * time.Hour
+			case ch == 'D':
+				d += time.Duration(n) * 24 * time.Hour
+			case ch == 'H' && inTime:
+				d += time.Duration(n) * time.Hour
+			case ch == 'M' && inTime:
+				d += time.Duration(n) * time.Minute
+			case ch == 'S' && inTime:
+				d += time.Duration(n) * time.Second
+			}
+		}
+	}
+	return d
+}
+
+// parseICS reads every VEVENT in text. Components nested inside an event
+// (VALARM reminders in Google and Outlook exports) have their own SUMMARY
+// and DESCRIPTION, so properties are only taken while the VEVENT itself is
+// the innermost open component.
+func parseICS(text string) []icsEvent {
+	var events []icsEvent
+	var cur *icsEvent
+	var duration time.Duration
+	var stack []string
+
+	for _, line := range unfoldICSLines(text) {
+		name, params, value := splitICSProperty(line)
+		if name == "" {
+			continue
+		}
+
+		switch name {
+		case "BEGIN":
+			stack = append(stack, strings.ToUpper(value))
+			if strings.EqualFold(value, "VEVENT") {
+				cur = &icsEvent{ExDates: make(map[string]bool)}
+				duration = 0
+			}
+			continue
+		case "END":
+			if len(stack) == 0 || !strings.EqualFold(stack[len(stack)-1], value) {
+				continue
+			}
+			stack = stack[:len(stack)-1]
+			if strings.EqualFold(value, "VEVENT") && cur != nil {
+				if cur.End.IsZero() {
+					switch {
+					case duration > 0:
+						cur.End = cur.Start.Add(duration)
+					case cur.AllDay:
+						cur.End = cur.Start.AddDate(0, 0, 1)
+					default:
+						cur.End = cur.Start
+					}
+				}
+				if !cur.Start.IsZero() && cur.Summary != "" {
+					events = append(events, *cur)
+				}
+				cur = nil
+			}
+			continue
+		}
+		if cur == nil || len(stack) == 0 || stack[len(stack)-1] != "VEVENT" {
+			continue
+		}
+
+		switch name {
+		case "UID":
+			cur.UID = value
+		case "SUMMARY":
+			cur.Summary = unescapeICSText(value)
+		case "DESCRIPTION":
+			cur.Description = unescapeICSText(value)
+		case "STATUS":
+			cur.Cancelled = strings.EqualFold(value, "CANCELLED")
+		case "DTSTART":
+			if t, allDay, err := parseICSTime(value, params); err == nil {
+				cur.Start = t
+				cur.AllDay = allDay
+			}
+		case "DTEND":
+			if t, _, err := parseICSTime(value, params); err == nil {
+				cur.End = t
+			}
+		case "DURATION":
+			duration = parseICSDuration(value)
+		case "RRULE":
+			cur.RRule = make(map[string]string)
+			for _, part := range strings.Split(value, ";") {
+				if eq := strings.Index(part, "="); eq >= 0 {
+					cur.RRule[strings.ToUpper(part[:eq])] = strings.ToUpper(part[eq+1:])
+				}
+			}
+		case "EXDATE":
+			for _, v := range strings.Split(value, ",") {
+				if t, _, err := parseICSTime(v, params); err == nil {
+					cur.ExDates[t.Format("20060102")] = true
+				}
+			}
+		case "RECURRENCE-ID":
+			if t, _, err := parseICSTime(value, params); err == nil {
+				cur.RecurrenceID = t.Format("20060102")
+			}
+		}
+	}
+
+	return events
+}
+
+// icsToEvents expands recurrences and converts every occurrence overlapping
+// [from, to] (both dates inclusive) into a ScheduleEvent.
+func icsToEvents(parsed []icsEvent, from, to time.Time) []ScheduleEvent {
+	// Instances edited in the calendar app are exported as separate VEVENTs with
+	// a RECURRENCE-ID; they replace the generated occurrence of the master.
+	overridden := make(map[string]map[string]bool)
+	for _, ev := range parsed {
+		if ev.RecurrenceID != "" && ev.UID != "" {
+			if overridden[ev.UID] == nil {
+				overridden[ev.UID] = make(map[string]bool)
+			}
+			overridden[ev.UID][ev.RecurrenceID] = true
+		}
+	}
+
+	var events []ScheduleEvent
+	for _, ev := range parsed {
+		if ev.Cancelled {
+			continue
+		}
+		for _, start := range icsOccurrences(ev, from, to) {
+			day := start.Format("20060102")
+			if ev.ExDates[day] || (ev.RecurrenceID == "" && overridden[ev.UID][day]) {
+				continue
+			}
+
+			end := start.Add(ev.End.Sub(ev.Start))
+			lastDay := start
+			if end.After(start) {
+				// DTEND is exclusive: an all-day event ending on the 4th covers up to the 3rd,
+				// and a timed event ending exactly at midnight doesn't reach the next day.
+				lastDay = end.Add(-time.Nanosecond)
+			}
+			lastDay = time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day(), 0, 0, 0, 0, time.Local)
+			startDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.Local)
+
+			if lastDay.Before(from) || startDay.After(to) {
+				continue
+			}
+
+			se := ScheduleEvent{
+				Date:   day,
+				Name:   ev.Summary,
+				Detail: ev.Description,
+				Source: "ics",
+				UID:    icsOccurrenceUID(ev, day),
+			}
+			if lastDay.After(startDay) {
+				se.EndDate = lastDay.Format("20060102")
+			}
+			events = append(events, se)
+		}
+	}
+
+	return events
+}
+
+// icsOccurrenceUID keeps the source UID for export. Each occurrence of a
+// recurring series (and each edited instance) is exported as an event of its
+// own, so those get the occurrence date appended.
+func icsOccurrenceUID(ev icsEvent, day string) string {
+	switch {
+	case ev.UID == "":
+		return ""
+	case ev.RecurrenceID != "":
+		return ev.UID + "_" + ev.RecurrenceID
+	case ev.RRule != nil:
+		return ev.UID + "_" + day
+	}
+	return ev.UID
+}
+
+var icsWeekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// icsRRuleParts are the RRULE parts icsOccurrences understands. A rule
+// with any other part (BYMONTHDAY, BYSETPOS, ...) would expand to wrong
+// dates, so only its first occurrence is shown.
+var icsRRuleParts = map[string]bool{
+	"FREQ": true, "INTERVAL": true, "COUNT": true, "UNTIL": true, "BYDAY": true, "WKST": true,
+}
+
+// icsByDay is one BYDAY entry: a weekday, with an ordinal for monthly rules
+// ("2TU" is the second Tuesday, "-1FR" the last Friday, 0 every one).
+type icsByDay struct {
+	n       int
+	weekday time.Weekday
+}
+
+// icsUnsupportedRule explains why icsOccurrences can't expand ev's RRULE,
+// or returns "" if it can (or ev doesn't repeat).
+func icsUnsupportedRule(ev icsEvent) string {
+	if ev.RRule == nil {
+		return ""
+	}
+	var parts []string
+	for part := range ev.RRule {
+		if !icsRRuleParts[part] {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) > 0 {
+		sort.Strings(parts)
+		return "unsupported RRULE part " + strings.Join(parts, ",")
+	}
+	byDay, ok := parseICSByDay(ev.RRule["BYDAY"])
+	freq := ev.RRule["FREQ"]
+	if !ok || len(byDay) > 0 && freq != "WEEKLY" && freq != "MONTHLY" || freq == "WEEKLY" && hasOrdinal(byDay) {
+		return "unsupported BYDAY " + ev.RRule["BYDAY"]
+	}
+	switch freq {
+	case "DAILY", "WEEKLY", "MONTHLY", "YEARLY":
+		return ""
+	}
+	return "unsupported FREQ " + freq
+}
+
+// icsReported remembers the unsupported rules already reported for each
+// source, so a dashboard refresh every few minutes doesn't repeat them.
+var icsReported = struct {
+	sync.Mutex
+	seen map[string]bool
+}{seen: make(map[string]bool)}
+
+// icsRuleNote describes an unsupported rule for the log, and reports false
+// if it was already reported for src.
+func icsRuleNote(src string, ev icsEvent, reason string) (string, bool) {
+	key := src + "\x00" + ev.Summary + "\x00" + reason
+	icsReported.Lock()
+	defer icsReported.Unlock()
+	if icsReported.seen[key] {
+		return "", false
+	}
+	icsReported.seen[key] = true
+	return fmt.Sprintf("ics: %s: %q: %s, showing the first occurrence only", icsSourceName(src), ev.Summary, reason), true
+}
+
+// icsOccurrences returns the start time of every occurrence up to `to`.
+// Supported RRULE parts: FREQ (DAILY/WEEKLY/MONTHLY/YEARLY), INTERVAL, COUNT,
+// UNTIL, and BYDAY for weekly and monthly rules. Without COUNT, expansion
+// starts just before `from`, so a series that began years ago still
+// reaches the window.
+func icsOccurrences(ev icsEvent, from, to time.Time) []time.Time {
+	if ev.RRule == nil || icsUnsupportedRule(ev) != "" {
+		return []time.Time{ev.Start}
+	}
+	byDay, _ := parseICSByDay(ev.RRule["BYDAY"])
+	freq := ev.RRule["FREQ"]
+
+	interval, _ := strconv.Atoi(ev.RRule["INTERVAL"])
+	if interval < 1 {
+		interval = 1
+	}
+	count, _ := strconv.Atoi(ev.RRule["COUNT"])
+
+	limit := to.AddDate(0, 0, 1)
+	if until := ev.RRule["UNTIL"]; until != "" {
+		if t, _, err := parseICSTime(until, nil); err == nil {
+			if len(until) == 8 {
+				t = t.AddDate(0, 0, 1)
+			} else {
+				t = t.Add(time.Second)
+			}
+			if t.Before(limit) {
+				limit = t
+			}
+		}
+	}
+
+	// COUNT numbers occurrences from DTSTART, so those series can't skip ahead.
+	skip := 0
+	if count == 0 {
+		skip = icsPeriodsBefore(ev.Start, from.Add(-ev.End.Sub(ev.Start)), freq, interval)
+	}
+
+	var out []time.Time
+	n := 0
+	emit := func(t time.Time) bool {
+		if !t.Before(limit) || (count > 0 && n >= count) {
+			return false
+		}
+		n++
+		out = append(out, t)
+		return true
+	}
+
+	start := ev.Start
+	for i := skip; i < skip+5000; i++ {
+		switch freq {
+		case "DAILY":
+			if !emit(start.AddDate(0, 0, i*interval)) {
+				return out
+			}
+		case "WEEKLY":
+			if len(byDay) == 0 {
+				if !emit(start.AddDate(0, 0, 7*i*interval)) {
+					return out
+				}
+				continue
+			}
+			weekStart := start.AddDate(0, 0, -int(start.Weekday())+7*i*interval)
+			for wd := time.Sunday; wd <= time.Saturday; wd++ {
+				if !containsWeekday(byDay, 0, wd) {
+					continue
+				}
+				t := weekStart.AddDate(0, 0, int(wd))
+				if t.Before(start) {
+					continue
+				}
+				if !emit(t) {
+					return out
+				}
+			}
+		case "MONTHLY":
+			if len(byDay) > 0 {
+				monthStart := time.Date(start.Year(), start.Month()+time.Month(i*interval), 1,
+					start.Hour(), start.Minute(), start.Second(), 0, start.Location())
+				if !monthStart.Before(limit) {
+					return out
+				}
+				for _, t := range monthlyByDay(monthStart, byDay) {
+					if t.Before(start) {
+						continue
+					}
+					if !emit(t) {
+						return out
+					}
+				}
+				continue
+			}
+			t := start.AddDate(0, i*interval, 0)
+			if t.Day() != start.Day() {
+				// e.g. the 31st in a 30-day month: RFC 5545 skips the instance.
+				if !t.Before(limit) {
+					return out
+				}
+				continue
+			}
+			if !emit(t) {
+				return out
+			}
+		case "YEARLY":
+			t := start.AddDate(i*interval, 0, 0)
+			if t.Day() != start.Day() {
+				if !t.Before(limit) {
+					return out
+				}
+				continue
+			}
+			if !emit(t) {
+				return out
+			}
+		}
+	}
+	return out
+}
+
+// icsPeriodsBefore returns how many whole FREQ periods can be skipped from
+// start without missing an occurrence at or after windowStart. It stays one
+// period short so the first partial week or month is still expanded.
+func icsPeriodsBefore(start, windowStart time.Time, freq string, interval int) int {
+	if !windowStart.After(start) {
+		return 0
+	}
+	days := int(dateOnlyUTC(windowStart).Sub(dateOnlyUTC(start)).Hours() / 24)
+	months := (windowStart.Year()-start.Year())*12 + int(windowStart.Month()-start.Month())
+
+	var n int
+	switch freq {
+	case "DAILY":
+		n = days / interval
+	case "WEEKLY":
+		n = days / (7 * interval)
+	case "MONTHLY":
+		n = months / interval
+	case "YEARLY":
+		n = months / 12 / interval
+	}
+	return max(n-1, 0)
+}
+
+func dateOnlyUTC(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// parseICSByDay parses a BYDAY list such as "MO,WE" or "2TU,-1FR". It
+// reports false if any entry cannot be read.
+func parseICSByDay(value string) ([]icsByDay, bool) {
+	if value == "" {
+		return nil, true
+	}
+	var days []icsByDay
+	for _, d := range strings.Split(value, ",") {
+		i := strings.IndexFunc(d, func(r rune) bool { return r >= 'A' && r <= 'Z' })
+		if i < 0 {
+			return nil, false
+		}
+		wd, ok := icsWeekdays[d[i:]]
+		if !ok {
+			return nil, false
+		}
+		n := 0
+		if i > 0 {
+			var err error
+			if n, err = strconv.Atoi(d[:i]); err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, false
+			}
+		}
+		days = append(days, icsByDay{n: n, weekday: wd})
+	}
+	return days, true
+}
+
+// monthlyByDay returns the days of monthStart's month matching byDay, in
+// date order, at monthStart's time of day.
+func monthlyByDay(monthStart time.Time, byDay []icsByDay) []time.Time {
+	daysIn := time.Date(monthStart.Year(), monthStart.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	var out []time.Time
+	for day := 1; day <= daysIn; day++ {
+		t := monthStart.AddDate(0, 0, day-1)
+		nth := (day-1)/7 + 1                // 2 for the second Tuesday
+		nthFromEnd := -((daysIn-day)/7 + 1) // -1 for the last Friday
+		for _, b := range byDay {
+			if b.weekday == t.Weekday() && (b.n == 0 || b.n == nth || b.n == nthFromEnd) {
+				out = append(out, t)
+				break
+			}
+		}
+	}
+	return out
+}
+
+func hasOrdinal(days []icsByDay) bool {
+	for _, d := range days {
+		if d.n != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func containsWeekday(days []icsByDay, n int, wd time.Weekday) bool {
+	for _, d := range days {
+		if d.n == n && d.weekday == wd {
+			return true
+		}
+	}
+	return false
+}
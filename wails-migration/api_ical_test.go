@@ -0,0 +1,443 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// icsDate parses a YYYYMMDD string as a local date for window boundaries.
+func icsDate(t *testing.T, s string) time.Time {
+	t.Helper()
+	d, err := time.ParseInLocation("20060102", s, time.Local)
+	if err != nil {
+		t.Fatalf("icsDate(%q): %v", s, err)
+	}
+	return d
+}
+
+// wrapICS builds a minimal VCALENDAR around the given VEVENT bodies.
+func wrapICS(events ...string) string {
+	var b strings.Builder
+	b.WriteString("BEGIN:VCALENDAR\r\nVERSION:2.0\r\nPRODID:-//test//EN\r\n")
+	for _, e := range events {
+		b.WriteString("BEGIN:VEVENT\r\n")
+		b.WriteString(strings.ReplaceAll(strings.TrimSpace(e), "\n", "\r\n"))
+		b.WriteString("\r\nEND:VEVENT\r\n")
+	}
+	b.WriteString("END:VCALENDAR\r\n")
+	return b.String()
+}
+
+func eventDates(events []ScheduleEvent) []string {
+	var dates []string
+	for _, e := range events {
+		dates = append(dates, e.Date)
+	}
+	return dates
+}
+
+// ============================================================
+// parseICS
+// ============================================================
+
+func TestParseICS_AllDayEvent(t *testing.T) {
+	text := wrapICS(`UID:a1
+DTSTART;VALUE=DATE:20260302
+DTEND;VALUE=DATE:20260303
+SUMMARY:개학식`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260301"), icsDate(t, "20260331"))
+
+	if len(got) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(got))
+	}
+	if got[0].Date != "20260302" || got[0].Name != "개학식" {
+		t.Errorf("got %+v", got[0])
+	}
+	if got[0].EndDate != "" {
+		t.Errorf("single-day event should have no EndDate, got %q", got[0].EndDate)
+	}
+	if got[0].Source != "ics" {
+		t.Errorf("Source: got %q, want ics", got[0].Source)
+	}
+}
+
+func TestParseICS_MultiDayAllDayEvent(t *testing.T) {
+	// DTEND is exclusive: 07-20 .. 07-24 covers the 20th through the 23rd.
+	text := wrapICS(`DTSTART;VALUE=DATE:20260720
+DTEND;VALUE=DATE:20260724
+SUMMARY:수련회`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260701"), icsDate(t, "20260731"))
+
+	if len(got) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(got))
+	}
+	if got[0].Date != "20260720" || got[0].EndDate != "20260723" {
+		t.Errorf("expected 20260720~20260723, got %s~%s", got[0].Date, got[0].EndDate)
+	}
+}
+
+func TestParseICS_MultiDayEventOverlappingWindowStartIsIncluded(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20260720
+DTEND;VALUE=DATE:20260820
+SUMMARY:여름방학`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260801"), icsDate(t, "20260831"))
+
+	if len(got) != 1 {
+		t.Fatalf("expected ongoing vacation to be included, got %d events", len(got))
+	}
+}
+
+func TestParseICS_TimedEventWithTZID(t *testing.T) {
+	text := wrapICS(`DTSTART;TZID=Asia/Seoul:20260310T140000
+DTEND;TZID=Asia/Seoul:20260310T160000
+SUMMARY:학부모 상담`)
+
+	parsed := parseICS(text)
+	if len(parsed) != 1 {
+		t.Fatalf("expected 1 parsed event, got %d", len(parsed))
+	}
+	if parsed[0].AllDay {
+		t.Error("timed event should not be all-day")
+	}
+	seoul, _ := time.LoadLocation("Asia/Seoul")
+	want := time.Date(2026, 3, 10, 14, 0, 0, 0, seoul)
+	if !parsed[0].Start.Equal(want) {
+		t.Errorf("Start: got %v, want %v", parsed[0].Start, want)
+	}
+}
+
+func TestParseICS_UTCTimeConvertedToLocal(t *testing.T) {
+	text := wrapICS(`DTSTART:20260310T050000Z
+SUMMARY:회의`)
+
+	parsed := parseICS(text)
+	if len(parsed) != 1 {
+		t.Fatalf("expected 1 parsed event, got %d", len(parsed))
+	}
+	if parsed[0].Start.Location() != time.Local {
+		t.Errorf("expected local time, got location %v", parsed[0].Start.Location())
+	}
+	if !parsed[0].Start.Equal(time.Date(2026, 3, 10, 5, 0, 0, 0, time.UTC)) {
+		t.Errorf("Start instant changed: %v", parsed[0].Start)
+	}
+}
+
+func TestParseICS_FoldedLinesAndEscapes(t *testing.T) {
+	text := wrapICS("DTSTART;VALUE=DATE:20260302\nSUMMARY:현장\n  체험\\, 학습\nDESCRIPTION:준비물\\n도시락")
+
+	parsed := parseICS(text)
+	if len(parsed) != 1 {
+		t.Fatalf("expected 1 parsed event, got %d", len(parsed))
+	}
+	if parsed[0].Summary != "현장 체험, 학습" {
+		t.Errorf("Summary: got %q", parsed[0].Summary)
+	}
+	if parsed[0].Description != "준비물\n도시락" {
+		t.Errorf("Description: got %q", parsed[0].Description)
+	}
+}
+
+func TestParseICS_CancelledEventSkipped(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20260302
+SUMMARY:취소된 행사
+STATUS:CANCELLED`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260301"), icsDate(t, "20260331"))
+	if len(got) != 0 {
+		t.Errorf("expected cancelled event to be skipped, got %d", len(got))
+	}
+}
+
+func TestParseICS_EventWithoutSummarySkipped(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20260302`)
+	if got := parseICS(text); len(got) != 0 {
+		t.Errorf("expected 0 events, got %d", len(got))
+	}
+}
+
+func TestParseICS_NestedAlarmDoesNotOverrideEvent(t *testing.T) {
+	text := wrapICS(`UID:a2
+DTSTART;VALUE=DATE:20260302
+SUMMARY:학부모 상담
+BEGIN:VALARM
+ACTION:DISPLAY
+SUMMARY:Alarm
+DESCRIPTION:Reminder
+TRIGGER:-PT15M
+END:VALARM
+DESCRIPTION:2층 상담실`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260301"), icsDate(t, "20260331"))
+
+	if len(got) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(got))
+	}
+	if got[0].Name != "학부모 상담" || got[0].Detail != "2층 상담실" {
+		t.Errorf("alarm properties leaked into the event: %+v", got[0])
+	}
+}
+
+func TestParseICS_DurationUsedWhenNoDTEND(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20260302
+DURATION:P3D
+SUMMARY:체험학습 주간`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260301"), icsDate(t, "20260331"))
+	if len(got) != 1 || got[0].EndDate != "20260304" {
+		t.Fatalf("expected 20260302~20260304, got %+v", got)
+	}
+}
+
+// ============================================================
+// RRULE expansion
+// ============================================================
+
+func TestICSRRule_WeeklyByDay(t *testing.T) {
+	// 2026-03-02 is a Monday.
+	text := wrapICS(`DTSTART;TZID=Asia/Seoul:20260302T150000
+DTEND;TZID=Asia/Seoul:20260302T160000
+RRULE:FREQ=WEEKLY;BYDAY=MO,WE
+SUMMARY:방과후`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260301"), icsDate(t, "20260315"))
+
+	want := []string{"20260302", "20260304", "20260309", "20260311"}
+	if strings.Join(eventDates(got), ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", eventDates(got), want)
+	}
+}
+
+func TestICSRRule_DailyWithCount(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20260302
+RRULE:FREQ=DAILY;COUNT=3
+SUMMARY:진단평가`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260301"), icsDate(t, "20260331"))
+
+	want := []string{"20260302", "20260303", "20260304"}
+	if strings.Join(eventDates(got), ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", eventDates(got), want)
+	}
+}
+
+func TestICSRRule_UntilIsInclusive(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20260302
+RRULE:FREQ=WEEKLY;UNTIL=20260316
+SUMMARY:주간 회의`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260301"), icsDate(t, "20260331"))
+
+	want := []string{"20260302", "20260309", "20260316"}
+	if strings.Join(eventDates(got), ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", eventDates(got), want)
+	}
+}
+
+func TestICSRRule_MonthlySkipsShortMonths(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20260131
+RRULE:FREQ=MONTHLY;COUNT=3
+SUMMARY:월말 정산`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260101"), icsDate(t, "20261231"))
+
+	want := []string{"20260131", "20260331", "20260531"}
+	if strings.Join(eventDates(got), ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", eventDates(got), want)
+	}
+}
+
+func TestICSRRule_MonthlyByDayOrdinal(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20260113
+RRULE:FREQ=MONTHLY;BYDAY=2TU;COUNT=3
+SUMMARY:학년 협의회`, `DTSTART;VALUE=DATE:20260130
+RRULE:FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20260331
+SUMMARY:월말 평가`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260101"), icsDate(t, "20261231"))
+
+	want := []string{"20260113", "20260210", "20260310", "20260130", "20260227", "20260327"}
+	if strings.Join(eventDates(got), ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", eventDates(got), want)
+	}
+}
+
+func TestICSRRule_UnsupportedPartShowsFirstOnly(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20260105
+RRULE:FREQ=MONTHLY;BYMONTHDAY=5,20
+SUMMARY:급식 신청`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260101"), icsDate(t, "20261231"))
+
+	if len(got) != 1 || got[0].Date != "20260105" {
+		t.Errorf("expected only 20260105, got %v", eventDates(got))
+	}
+}
+
+func TestICSRRule_YearlyOnlyInsideWindow(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20200505
+RRULE:FREQ=YEARLY
+SUMMARY:어린이날`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260401"), icsDate(t, "20260531"))
+
+	if len(got) != 1 || got[0].Date != "20260505" {
+		t.Errorf("expected only 20260505, got %v", eventDates(got))
+	}
+}
+
+func TestICSRRule_OldDailySeriesReachesWindow(t *testing.T) {
+	text := wrapICS(`DTSTART;TZID=Asia/Seoul:20080303T083000
+DTEND;TZID=Asia/Seoul:20080303T084000
+RRULE:FREQ=DAILY
+SUMMARY:아침 독서`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20261001"), icsDate(t, "20261003"))
+
+	want := []string{"20261001", "20261002", "20261003"}
+	if strings.Join(eventDates(got), ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", eventDates(got), want)
+	}
+}
+
+func TestICSRRule_MultiDayOccurrenceBeforeWindowIsIncluded(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20100301
+DTEND;VALUE=DATE:20100306
+RRULE:FREQ=MONTHLY
+SUMMARY:생활 점검 주간`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20261003"), icsDate(t, "20261010"))
+
+	if len(got) != 1 || got[0].Date != "20261001" || got[0].EndDate != "20261005" {
+		t.Errorf("expected 20261001-20261005, got %+v", got)
+	}
+}
+
+func TestICSRRule_ExDateExcluded(t *testing.T) {
+	text := wrapICS(`DTSTART;VALUE=DATE:20260302
+RRULE:FREQ=DAILY;COUNT=3
+EXDATE;VALUE=DATE:20260303
+SUMMARY:보충수업`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260301"), icsDate(t, "20260331"))
+
+	want := []string{"20260302", "20260304"}
+	if strings.Join(eventDates(got), ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", eventDates(got), want)
+	}
+}
+
+func TestICSRRule_RecurrenceIDOverridesInstance(t *testing.T) {
+	text := wrapICS(`UID:series-1
+DTSTART;VALUE=DATE:20260302
+RRULE:FREQ=DAILY;COUNT=2
+SUMMARY:동아리`, `UID:series-1
+RECURRENCE-ID;VALUE=DATE:20260303
+DTSTART;VALUE=DATE:20260305
+SUMMARY:동아리 (변경)`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260301"), icsDate(t, "20260331"))
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %d: %+v", len(got), got)
+	}
+	if got[0].Date != "20260302" || got[1].Date != "20260305" || got[1].Name != "동아리 (변경)" {
+		t.Errorf("unexpected events: %+v", got)
+	}
+}
+
+func TestICSRRule_OccurrencesGetDistinctUIDs(t *testing.T) {
+	text := wrapICS(`UID:series-1
+DTSTART;VALUE=DATE:20260302
+RRULE:FREQ=DAILY;COUNT=2
+SUMMARY:동아리`, `UID:series-1
+RECURRENCE-ID;VALUE=DATE:20260303
+DTSTART;VALUE=DATE:20260305
+SUMMARY:동아리 (변경)`, `UID:single-1
+DTSTART;VALUE=DATE:20260310
+SUMMARY:개학식`)
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260301"), icsDate(t, "20260331"))
+
+	var uids []string
+	for _, e := range got {
+		uids = append(uids, e.UID)
+	}
+	want := []string{"series-1_20260302", "series-1_20260303", "single-1"}
+	if strings.Join(uids, ",") != strings.Join(want, ",") {
+		t.Errorf("got %v, want %v", uids, want)
+	}
+}
+
+// ============================================================
+// readICSSource / fetchICSEvents
+// ============================================================
+
+func TestFetchICSEvents_LocalFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "school.ics")
+	text := wrapICS(`DTSTART;VALUE=DATE:20260302
+SUMMARY:입학식`)
+	if err := os.WriteFile(path, []byte(text), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, _, err := fetchICSEvents(context.Background(), []string{path, "  "}, "20260301", "20260331")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 || got[0].Name != "입학식" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestFetchICSEvents_MissingFileReportsErrorButKeepsOthers(t *testing.T) {
+	good := filepath.Join(t.TempDir(), "good.ics")
+	if err := os.WriteFile(good, []byte(wrapICS("DTSTART;VALUE=DATE:20260302\nSUMMARY:행사")), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	got, _, err := fetchICSEvents(context.Background(), []string{filepath.Join(t.TempDir(), "missing.ics"), good}, "20260301", "20260331")
+	if err == nil {
+		t.Error("expected an error for the missing file")
+	}
+	if len(got) != 1 {
+		t.Errorf("expected events from the readable file, got %d", len(got))
+	}
+}
+
+func TestFetchICSEvents_KeepsPrivateURLPathOutOfMessages(t *testing.T) {
+	body := wrapICS(`DTSTART;VALUE=DATE:20260305
+RRULE:FREQ=MONTHLY;BYMONTHDAY=5,20
+SUMMARY:급식 신청`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "missing") {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, body)
+	}))
+	defer srv.Close()
+	good := srv.URL + "/calendar/ical/private-s3cr3t/basic.ics"
+	missing := srv.URL + "/calendar/ical/missing-s3cr3t/basic.ics"
+
+	_, notes, err := fetchICSEvents(context.Background(), []string{good, missing}, "20260301", "20260331")
+	if err == nil || strings.Contains(err.Error(), "s3cr3t") {
+		t.Errorf("err = %v, want an error without the URL path", err)
+	}
+	if len(notes) != 1 || strings.Contains(notes[0], "s3cr3t") {
+		t.Errorf("notes = %q, want one note without the URL path", notes)
+	}
+
+	if _, again, _ := fetchICSEvents(context.Background(), []string{good}, "20260301", "20260331"); len(again) != 0 {
+		t.Errorf("rule reported twice: %q", again)
+	}
+}
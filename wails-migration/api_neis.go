@@ -1,9 +1,9 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 	"strings"
 )
@@ -22,18 +22,23 @@ type SchoolInfo struct {
 }
 
 type ScheduleEvent struct {
-	Date   string `json:"date"`
-	Name   string `json:"name"`
-	Detail string `json:"detail,omitempty"`
+	Date    string   `json:"date"`
+	EndDate string   `json:"endDate,omitempty"` // last day (inclusive) of a multi-day event
+	Name    string   `json:"name"`
+	Detail  string   `json:"detail,omitempty"`
+	Source  string   `json:"source,omitempty"`  // "neis", "sheet", "ics" or "personal"
+	Sources []string `json:"sources,omitempty"` // every source that reported this event, set by mergeEvents
+	Holiday bool     `json:"holiday,omitempty"` // no classes that day (NEIS 휴업일/공휴일)
+	UID     string   `json:"uid,omitempty"`     // calendar UID of ICS and personal events, kept on export
 }
 
-func fetchMeals(apiKey, officeCode, schoolCode, fromDate, toDate string) ([]MealData, error) {
+func fetchMeals(ctx context.Context, apiKey, officeCode, schoolCode, fromDate, toDate string) ([]MealData, error) {
 	u := fmt.Sprintf(
-		"https://open.neis.go.kr/hub/mealServiceDietInfo?KEY=%s&ATPT_OFCDC_SC_CODE=%s&SD_SCHUL_CODE=%s&MLSV_FROM_YMD=%s&MLSV_TO_YMD=%s&Type=json",
-		apiKey, officeCode, schoolCode, fromDate, toDate,
+		"%s/mealServiceDietInfo?KEY=%s&ATPT_OFCDC_SC_CODE=%s&SD_SCHUL_CODE=%s&MLSV_FROM_YMD=%s&MLSV_TO_YMD=%s&Type=json",
+		neisBaseURL, apiKey, officeCode, schoolCode, fromDate, toDate,
 	)
 
-	resp, err := http.Get(u)
+	resp, err := neisGet(ctx, apiKey, u)
 	if err != nil {
 		return nil, fmt.Errorf("급식 네트워크 오류: %w", err)
 	}
@@ -51,7 +56,7 @@ func fetchMeals(apiKey, officeCode, schoolCode, fromDate, toDate string) ([]Meal
 	}
 
 	if raw.Result != nil {
-		return nil, fmt.Errorf("급식 NEIS API 오류 (%s): %s", raw.Result.Code, raw.Result.Message)
+		return nil, &neisAPIError{Prefix: "급식 ", Code: raw.Result.Code, Message: raw.Result.Message}
 	}
 
 	if len(raw.MealServiceDietInfo) < 2 {
@@ -89,13 +94,13 @@ func fetchMeals(apiKey, officeCode, schoolCode, fromDate, toDate string) ([]Meal
 	return meals, nil
 }
 
-func searchSchool(apiKey, schoolName string) ([]SchoolInfo, error) {
+func searchSchool(ctx context.Context, apiKey, schoolName string) ([]SchoolInfo, error) {
 	u := fmt.Sprintf(
-		"https://open.neis.go.kr/hub/schoolInfo?KEY=%s&SCHUL_NM=%s&Type=json",
-		apiKey, url.QueryEscape(schoolName),
+		"%s/schoolInfo?KEY=%s&SCHUL_NM=%s&Type=json",
+		neisBaseURL, apiKey, url.QueryEscape(schoolName),
 	)
 
-	resp, err := http.Get(u)
+	resp, err := neisGet(ctx, apiKey, u)
 	if err != nil {
 		return nil, fmt.Errorf("네트워크 오류: %w", err)
 	}
@@ -114,7 +119,7 @@ func searchSchool(apiKey, schoolName string) ([]SchoolInfo, error) {
 
 	// NEIS API error response (rate limit, invalid key, etc.)
 	if raw.Result != nil {
-		return nil, fmt.Errorf("NEIS API 오류 (%s): %s", raw.Result.Code, raw.Result.Message)
+		return nil, &neisAPIError{Code: raw.Result.Code, Message: raw.Result.Message}
 	}
 
 	if len(raw.SchoolInfo) < 2 {
@@ -146,13 +151,13 @@ func searchSchool(apiKey, schoolName string) ([]SchoolInfo, error) {
 	return results, nil
 }
 
-func fetchSchoolEvents(apiKey, officeCode, schoolCode, fromDate, toDate string) ([]ScheduleEvent, error) {
+func fetchSchoolEvents(ctx context.Context, apiKey, officeCode, schoolCode, fromDate, toDate string) ([]ScheduleEvent, error) {
 	u := fmt.Sprintf(
-		"https://open.neis.go.kr/hub/SchoolSchedule?KEY=%s&ATPT_OFCDC_SC_CODE=%s&SD_SCHUL_CODE=%s&AA_FROM_YMD=%s&AA_TO_YMD=%s&Type=json",
-		apiKey, officeCode, schoolCode, fromDate, toDate,
+		"%s/SchoolSchedule?KEY=%s&ATPT_OFCDC_SC_CODE=%s&SD_SCHUL_CODE=%s&AA_FROM_YMD=%s&AA_TO_YMD=%s&pSize=1000&Type=json",
+		neisBaseURL, apiKey, officeCode, schoolCode, fromDate, toDate,
 	)
 
-	resp, err := http.Get(u)
+	resp, err := neisGet(ctx, apiKey, u)
 	if err != nil {
 		return nil, fmt.Errorf("행사 네트워크 오류: %w", err)
 	}
@@ -170,7 +175,7 @@ func fetchSchoolEvents(apiKey, officeCode, schoolCode, fromDate, toDate string)
 	}
 
 	if raw.Result != nil {
-		return nil, fmt.Errorf("행사 NEIS API 오류 (%s): %s", raw.Result.Code, raw.Result.Message)
+		return nil, &neisAPIError{Prefix: "행사 ", Code: raw.Result.Code, Message: raw.Result.Message}
 	}
 
 	if len(raw.SchoolSchedule) < 2 {
@@ -179,9 +184,10 @@ func fetchSchoolEvents(apiKey, officeCode, schoolCode, fromDate, toDate string)
 
 	var rowData struct {
 		Row []struct {
-			AA_YMD      string `json:"AA_YMD"`
-			EVENT_NM    string `json:"EVENT_NM"`
-			EVENT_CNTNT string `json:"EVENT_CNTNT"`
+			AA_YMD        string `json:"AA_YMD"`
+			EVENT_NM      string `json:"EVENT_NM"`
+			EVENT_CNTNT   string `json:"EVENT_CNTNT"`
+			SBTR_DD_SC_NM string `json:"SBTR_DD_SC_NM"` // 수업공제일명: 휴업일, 공휴일 or 해당없음
 		} `json:"row"`
 	}
 	if err := json.Unmarshal(raw.SchoolSchedule[1], &rowData); err != nil {
@@ -191,9 +197,11 @@ func fetchSchoolEvents(apiKey, officeCode, schoolCode, fromDate, toDate string)
 	var events []ScheduleEvent
 	for _, row := range rowData.Row {
 		events = append(events, ScheduleEvent{
-			Date:   row.AA_YMD,
-			Name:   row.EVENT_NM,
-			Detail: row.EVENT_CNTNT,
+			Date:    row.AA_YMD,
+			Name:    row.EVENT_NM,
+			Detail:  row.EVENT_CNTNT,
+			Source:  "neis",
+			Holiday: row.SBTR_DD_SC_NM != "" && row.SBTR_DD_SC_NM != "해당없음",
 		})
 	}
 
@@ -1,9 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"regexp"
 	"strconv"
@@ -153,14 +153,14 @@ func csvToTimetableData(rows [][]string) *TimetableData {
 	return &TimetableData{Headers: headers, Periods: periods, Subjects: subjects}
 }
 
-func fetchTimetableFromSheet(spreadsheetURL string) (*TimetableData, error) {
+func fetchTimetableFromSheet(ctx context.Context, spreadsheetURL string) (*TimetableData, error) {
 	sheetID := extractSpreadsheetID(spreadsheetURL)
 	if sheetID == "" {
 		return nil, nil
 	}
 
 	csvURL := fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s/gviz/tq?tqx=out:csv", sheetID)
-	resp, err := http.Get(csvURL)
+	resp, err := httpGet(ctx, csvURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -179,14 +179,14 @@ func fetchTimetableFromSheet(spreadsheetURL string) (*TimetableData, error) {
 	return csvToTimetableData(rows), nil
 }
 
-func fetchEventsFromSheet(spreadsheetURL string) ([]ScheduleEvent, error) {
+func fetchEventsFromSheet(ctx context.Context, spreadsheetURL string) ([]ScheduleEvent, error) {
 	sheetID := extractSpreadsheetID(spreadsheetURL)
 	if sheetID == "" {
 		return nil, nil
 	}
 
 	csvURL := fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s/gviz/tq?tqx=out:csv&sheet=%s", sheetID, url.QueryEscape("행사"))
-	resp, err := http.Get(csvURL)
+	resp, err := httpGet(ctx, csvURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -239,7 +239,7 @@ func csvToEvents(rows [][]string) []ScheduleEvent {
 			continue
 		}
 
-		ev := ScheduleEvent{Date: dateStr, Name: name}
+		ev := ScheduleEvent{Date: dateStr, Name: name, Source: "sheet"}
 		if len(cols) > 2 {
 			detail := strings.TrimSpace(cols[2])
 			if detail != "" {
@@ -271,14 +271,14 @@ type StudyPlanResult struct {
 	CurrentIndex int              `json:"currentIndex"` // index of block containing today, -1 if none
 }
 
-func fetchStudyPlanFromSheet(spreadsheetURL string) (*StudyPlanResult, error) {
+func fetchStudyPlanFromSheet(ctx context.Context, spreadsheetURL string) (*StudyPlanResult, error) {
 	sheetID := extractSpreadsheetID(spreadsheetURL)
 	if sheetID == "" {
 		return nil, nil
 	}
 
 	csvURL := fmt.Sprintf("https://docs.google.com/spreadsheets/d/%s/gviz/tq?tqx=out:csv&sheet=%s", sheetID, url.QueryEscape("주학습계획안"))
-	resp, err := http.Get(csvURL)
+	resp, err := httpGet(ctx, csvURL, nil)
 	if err != nil {
 		return nil, err
 	}
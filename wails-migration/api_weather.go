@@ -1,9 +1,9 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 )
 
 type WeatherData struct {
@@ -14,13 +14,13 @@ type WeatherData struct {
 	PrecipitationProbability float64 `json:"precipitationProbability"`
 }
 
-func fetchWeather(lat, lon float64) (*WeatherData, error) {
+func fetchWeather(ctx context.Context, lat, lon float64) (*WeatherData, error) {
 	url := fmt.Sprintf(
 		"https://api.open-meteo.com/v1/forecast?latitude=%f&longitude=%f&current_weather=true&daily=weather_code,temperature_2m_max,temperature_2m_min,precipitation_probability_max&timezone=Asia/Seoul&forecast_days=1",
 		lat, lon,
 	)
 
-	resp, err := http.Get(url)
+	resp, err := httpGet(ctx, url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -1,85 +1,423 @@
 package main
 
 import (
+	"archive/zip"
+	"bytes"
 	"context"
-	"encoding/base64"
+	"errors"
 	"fmt"
-	"mime"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
 
-	"github.com/google/uuid"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
 type App struct {
 	ctx        context.Context
 	neisAPIKey string
+
+	quit             chan struct{} // closed on shutdown to stop background loops
+	remindersChanged chan struct{}
+	holidaysChanged  chan struct{}
+	trayMuteChanged  chan struct{} // read only by the tray loop
+	trayMuted        atomic.Bool   // state for the tray's mute checkbox
+	calendar         schoolCalendar
+	bells            *bellScheduler
+	background       *backgroundRotator
+	admin            adminLock
+	quitting         atomic.Bool // set once QuitApp passed the PIN check
+	keyRing          neisKeyRing
+	sources          sourceCache
+
+	streamSeq   atomic.Int64 // last StreamDashboardData id
+	fetchMu     sync.Mutex
+	fetchCtx    context.Context // shared by dashboard fetches until cancelled
+	cancelFetch context.CancelFunc
 }
 
 func NewApp(neisAPIKey string) *App {
-	return &App{neisAPIKey: neisAPIKey}
+	return &App{
+		neisAPIKey:       neisAPIKey,
+		quit:             make(chan struct{}),
+		remindersChanged: make(chan struct{}, 1),
+		holidaysChanged:  make(chan struct{}, 1),
+		trayMuteChanged:  make(chan struct{}, 1),
+		bells:            newBellScheduler(),
+		background:       newBackgroundRotator(),
+	}
 }
 
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
+	if p, err := loadPolicy(policyPaths()); err != nil {
+		runtime.LogError(a.ctx, "Ignoring admin policy: "+err.Error())
+	} else {
+		activePolicy = p
+	}
+	onSettingsRecovered = func(status SettingsStatus) {
+		runtime.EventsEmit(a.ctx, "settingsRecovered", status)
+	}
+	onPersonalEventsError = func(err error) {
+		runtime.LogError(a.ctx, "Personal events: "+err.Error())
+	}
+	if err := applyNetworkSettings(loadSettings()); err != nil {
+		runtime.LogError(a.ctx, "Ignoring network settings: "+err.Error())
+	}
+	a.maintainBackgrounds(false)
 	a.setupTray()
+	go a.runReminders()
+	go a.runBells()
+	go a.runHolidays()
+	go a.runBackgrounds()
 }
 
-func (a *App) shutdown(ctx context.Context) {}
-
-// getEffectiveAPIKey returns the user's custom key if enabled, otherwise the built-in key.
-func (a *App) getEffectiveAPIKey() string {
-	s := loadSettings()
-	if s.UseCustomAPIKey && s.CustomAPIKey != "" {
-		return s.CustomAPIKey
-	}
-	return a.neisAPIKey
+func (a *App) shutdown(ctx context.Context) {
+	close(a.quit)
 }
 
 // ===== Settings bindings =====
 
-func (a *App) GetSettings() Settings {
-	return loadSettings()
+// SettingsView is Settings as shown in the settings UI, with the keys the
+// admin policy locks.
+type SettingsView struct {
+	Settings
+	LockedFields []string `json:"lockedFields"`
+}
+
+// GetSettings leaves out the PIN hash and masks the API key; no binding
+// returns a raw key.
+func (a *App) GetSettings() SettingsView {
+	s := loadSettings()
+	s.AdminPINHash = ""
+	s.CustomAPIKey = maskSecret(s.CustomAPIKey)
+	s.ProxyURL = maskProxyURL(s.ProxyURL)
+	return SettingsView{Settings: s, LockedFields: activePolicy.lockedFields()}
 }
 
-func (a *App) SaveSettings(s Settings) {
-	if err := saveSettings(s); err != nil {
+// GetDefaultSettings returns the values a fresh install starts with, for the
+// settings window's reset button.
+func (a *App) GetDefaultSettings() Settings {
+	return defaultSettings
+}
+
+// SaveSettings returns an empty string on success, or an error message when
+// the admin PIN is needed, s changes a field locked by the admin policy or
+// cannot be written. The PIN itself only changes through SetAdminPIN.
+func (a *App) SaveSettings(s Settings) string {
+	var msg string
+	_, err := updateSettings(func(current *Settings) bool {
+		if !a.admin.allowed(current.AdminPINHash, time.Now()) {
+			msg = pinRequiredMessage
+			return false
+		}
+		s.AdminPINHash = current.AdminPINHash
+		if s.CustomAPIKey != "" && s.CustomAPIKey == maskSecret(current.CustomAPIKey) {
+			s.CustomAPIKey = current.CustomAPIKey
+		}
+		s.ProxyURL = restoreProxyPassword(s.ProxyURL, current.ProxyURL)
+		keepBackendFields(&s, *current)
+		if changed := activePolicy.changedFields(s); len(changed) > 0 {
+			msg = "관리자 정책으로 잠긴 설정은 변경할 수 없습니다: " + strings.Join(changed, ", ")
+			return false
+		}
+		if _, err := newTransport(s); err != nil {
+			msg = "네트워크 설정 오류: " + err.Error()
+			return false
+		}
+		*current = s
+		return true
+	})
+	if msg != "" {
+		return msg
+	}
+	if err != nil {
 		runtime.LogError(a.ctx, "Failed to save settings: "+err.Error())
+		return "설정 저장 실패: " + err.Error()
+	}
+	if err := applyNetworkSettings(loadSettings()); err != nil {
+		runtime.LogError(a.ctx, "Failed to apply network settings: "+err.Error())
 	}
+	a.bells.notify()
+	a.background.notify()
+	a.notifyHolidaysChanged()
 	runtime.EventsEmit(a.ctx, "settingsChanged")
+	return ""
+}
+
+// keepBackendFields takes the fields the app changes behind the settings
+// window from current: the window sends them back as it loaded them, so a
+// save would otherwise undo a mute from the tray, pruned countdowns or a
+// background cleanup. Backgrounds and sounds the window imported since are
+// kept unless their file is gone.
+func keepBackendFields(s *Settings, current Settings) {
+	s.MuteBellsDate = current.MuteBellsDate
+	s.Countdowns = current.Countdowns
+
+	backgrounds := slices.Clone(current.CustomBackgrounds)
+	for _, bg := range s.CustomBackgrounds {
+		if slices.ContainsFunc(current.CustomBackgrounds, func(c CustomBackground) bool { return c.Id == bg.Id }) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(backgroundsDir(), bg.FileName)); err == nil {
+			backgrounds = append(backgrounds, bg)
+		} else {
+			clearBackgroundRefs(s, bg.Id)
+		}
+	}
+	s.CustomBackgrounds = backgrounds
+
+	sounds := slices.Clone(current.CustomSounds)
+	var gone []string
+	for _, cs := range s.CustomSounds {
+		if _, ok := findCustomSound(current, cs.Id); ok {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(soundsDir(), cs.FileName)); err == nil {
+			sounds = append(sounds, cs)
+		} else {
+			gone = append(gone, cs.Id)
+		}
+	}
+	for _, id := range gone {
+		removeCustomSound(s, id)
+	}
+	s.CustomSounds = sounds
+}
+
+// GetSettingsStatus reports whether settings.json was damaged and had to be
+// restored from a backup or reset, so the UI can tell the user.
+func (a *App) GetSettingsStatus() SettingsStatus {
+	return getSettingsStatus()
+}
+
+// ===== Admin PIN =====
+
+const pinRequiredMessage = "관리자 PIN을 입력해야 합니다"
+
+func (a *App) GetPINStatus() PINStatus {
+	return a.admin.status(loadSettings().AdminPINHash, time.Now())
+}
+
+// UnlockAdmin starts an admin session. Returns an empty string on success,
+// or an error message.
+func (a *App) UnlockAdmin(pin string) string {
+	ok, retryAfter := a.admin.unlock(loadSettings().AdminPINHash, pin, time.Now())
+	switch {
+	case ok:
+		return ""
+	case retryAfter > 0:
+		return fmt.Sprintf("PIN 입력 시도가 너무 많습니다. %d초 후 다시 시도하세요", ceilSeconds(retryAfter))
+	default:
+		return "PIN이 올바르지 않습니다"
+	}
+}
+
+// LockAdmin ends the admin session, e.g. when the settings overlay closes.
+func (a *App) LockAdmin() {
+	a.admin.lock()
+}
+
+// SetAdminPIN sets the admin PIN, or removes it when pin is empty.
+// Returns an empty string on success, or an error message.
+func (a *App) SetAdminPIN(pin string) string {
+	if containsString(activePolicy.lockedFields(), "adminPinHash") {
+		return "관리자 정책으로 잠긴 설정은 변경할 수 없습니다: adminPinHash"
+	}
+	if pin != "" && !pinPattern.MatchString(pin) {
+		return "PIN은 숫자 4~8자리여야 합니다"
+	}
+	var msg string
+	_, err := updateSettings(func(s *Settings) bool {
+		if !a.admin.allowed(s.AdminPINHash, time.Now()) {
+			msg = pinRequiredMessage
+			return false
+		}
+		if pin == "" {
+			s.AdminPINHash = ""
+			return true
+		}
+		hash, err := hashPIN(pin)
+		if err != nil {
+			msg = "PIN 설정 실패: " + err.Error()
+			return false
+		}
+		s.AdminPINHash = hash
+		// Keep the session that set the PIN unlocked.
+		a.admin.unlock(hash, pin, time.Now())
+		return true
+	})
+	if msg != "" {
+		return msg
+	}
+	if err != nil {
+		return "설정 저장 실패: " + err.Error()
+	}
+	return ""
+}
+
+// requireAdmin reports whether a guarded action may run. When the PIN is
+// needed, the window is shown and "pinRequired" is emitted with the action
+// ("close" or "quit") so the UI can ask for the PIN and retry it.
+func (a *App) requireAdmin(action string) bool {
+	if a.admin.allowed(loadSettings().AdminPINHash, time.Now()) {
+		return true
+	}
+	runtime.WindowShow(a.ctx)
+	runtime.EventsEmit(a.ctx, "pinRequired", action)
+	return false
 }
 
 // ===== Dashboard data =====
 
 type DashboardData struct {
-	Weather    *WeatherData     `json:"weather"`
-	AirQuality *AirQualityData  `json:"airQuality"`
-	Meals      []MealData       `json:"meals"`
-	Events     []ScheduleEvent  `json:"events"`
-	Timetable  *TimetableData   `json:"timetable"`
-	StudyPlan  *StudyPlanResult `json:"studyPlan"`
+	Weather    *WeatherData            `json:"weather"`
+	AirQuality *AirQualityData         `json:"airQuality"`
+	Meals      []MealData              `json:"meals"`
+	Events     []ScheduleEvent         `json:"events"`
+	Timetable  *TimetableData          `json:"timetable"`
+	StudyPlan  *StudyPlanResult        `json:"studyPlan"`
+	Sources    map[string]SourceStatus `json:"sources"` // diagnostics by source name
+	Canceled   bool                    `json:"canceled,omitempty"`
+}
+
+// SourceStatus is the outcome of one data source in a dashboard fetch.
+type SourceStatus struct {
+	Status    string         `json:"status"` // "ok", "error" or "skipped"
+	Error     string         `json:"error,omitempty"`
+	Key       *NEISKeyStatus `json:"key,omitempty"`       // the NEIS key used, for NEIS sources
+	ElapsedMs int64          `json:"elapsedMs,omitempty"` // time the source took
 }
 
+// neisResult is a NEIS fetch together with the key that served it.
+type neisResult[T any] struct {
+	data T
+	key  neisKey
+}
+
+// FetchDashboardData fetches every source in parallel. Each request has its
+// own timeout, and CancelDashboardFetch abandons the whole call. Sources go
+// through a.sources, so overlapping calls share their requests.
 func (a *App) FetchDashboardData() DashboardData {
+	return a.fetchDashboard(a.dashboardContext(), nil)
+}
+
+// DashboardDone is the payload of the "dashboardDone" event that ends a
+// StreamDashboardData fetch.
+type DashboardDone struct {
+	Data      DashboardData `json:"data"` // Data.Sources holds the per-source timings
+	ElapsedMs int64         `json:"elapsedMs"`
+}
+
+// StreamDashboardData starts a dashboard fetch and returns its id at once.
+// Each section is emitted as "dashboardSection" (id, section, data) as soon
+// as it arrives: "weather", "airQuality", "meals", "events" (re-sent merged
+// as every event source lands), "timetable" and "studyPlan". The fetch ends
+// with "dashboardDone" (id, DashboardDone), also when cancelled.
+func (a *App) StreamDashboardData() int64 {
+	id := a.streamSeq.Add(1)
+	ctx := a.dashboardContext()
+	go func() {
+		start := time.Now()
+		data := a.fetchDashboard(ctx, func(section string, value any) {
+			runtime.EventsEmit(a.ctx, "dashboardSection", id, section, value)
+		})
+		runtime.EventsEmit(a.ctx, "dashboardDone", id, DashboardDone{
+			Data:      data,
+			ElapsedMs: time.Since(start).Milliseconds(),
+		})
+	}()
+	return id
+}
+
+// fetchDashboard fetches every source in parallel. section, when set, is
+// called with each section's data as it arrives; skipped sections are not
+// reported.
+func (a *App) fetchDashboard(ctx context.Context, section func(name string, data any)) DashboardData {
 	s := loadSettings()
-	apiKey := a.getEffectiveAPIKey()
-	result := DashboardData{}
+	keys := a.neisKeys(s)
+	result := DashboardData{Sources: make(map[string]SourceStatus)}
+	personalEvents := personalScheduleEvents(loadPersonalEvents(), todayStr())
 
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
+	report := func(source string, start time.Time, err error) {
+		st := SourceStatus{Status: "ok"}
+		if err != nil {
+			st = SourceStatus{Status: "error", Error: err.Error()}
+		}
+		st.ElapsedMs = time.Since(start).Milliseconds()
+		mu.Lock()
+		result.Sources[source] = st
+		mu.Unlock()
+	}
+	skip := func(source string) {
+		mu.Lock()
+		result.Sources[source] = SourceStatus{Status: "skipped"}
+		mu.Unlock()
+	}
+	// reportNEIS also records the key that served the call and its state
+	// afterwards.
+	reportNEIS := func(source string, start time.Time, key neisKey, err error) {
+		report(source, start, err)
+		if key.Value == "" {
+			return
+		}
+		ks := a.keyRing.status(key, time.Now())
+		mu.Lock()
+		st := result.Sources[source]
+		st.Key = &ks
+		result.Sources[source] = st
+		mu.Unlock()
+	}
+	emit := func(name string, data any) {
+		if section != nil && ctx.Err() == nil {
+			section(name, data)
+		}
+	}
+
+	var neisEvents, sheetEvents, icsEvents []ScheduleEvent
+	mergedEvents := func() []ScheduleEvent {
+		events := mergeEventsWithLimit(s.eventLimit(), neisEvents, sheetEvents, icsEvents, personalEvents)
+		if events == nil {
+			events = []ScheduleEvent{}
+		}
+		return events
+	}
+	// setEvents stores one event source and emits the merge so far.
+	setEvents := func(dst *[]ScheduleEvent, events []ScheduleEvent) {
+		mu.Lock()
+		*dst = events
+		merged := mergedEvents()
+		mu.Unlock()
+		emit("events", merged)
+	}
+
 	// Weather
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		if s.Latitude != 0 || s.Longitude != 0 {
-			w, _ := fetchWeather(s.Latitude, s.Longitude)
+			start := time.Now()
+			key := fmt.Sprintf("weather|%f|%f", s.Latitude, s.Longitude)
+			w, err := cachedFetch(&a.sources, ctx, key, func(ctx context.Context) (*WeatherData, error) {
+				return fetchWeather(ctx, s.Latitude, s.Longitude)
+			})
 			mu.Lock()
 			result.Weather = w
 			mu.Unlock()
+			report("weather", start, err)
+			emit("weather", w)
+		} else {
+			skip("weather")
 		}
 	}()
 
@@ -88,10 +426,18 @@ func (a *App) FetchDashboardData() DashboardData {
 	go func() {
 		defer wg.Done()
 		if s.Latitude != 0 || s.Longitude != 0 {
-			aq, _ := fetchAirQuality(s.Latitude, s.Longitude)
+			start := time.Now()
+			key := fmt.Sprintf("airQuality|%f|%f", s.Latitude, s.Longitude)
+			aq, err := cachedFetch(&a.sources, ctx, key, func(ctx context.Context) (*AirQualityData, error) {
+				return fetchAirQuality(ctx, s.Latitude, s.Longitude)
+			})
 			mu.Lock()
 			result.AirQuality = aq
 			mu.Unlock()
+			report("airQuality", start, err)
+			emit("airQuality", aq)
+		} else {
+			skip("airQuality")
 		}
 	}()
 
@@ -99,38 +445,51 @@ func (a *App) FetchDashboardData() DashboardData {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if apiKey != "" && s.SchoolCode != "" && s.OfficeCode != "" {
+		if len(keys) > 0 && s.SchoolCode != "" && s.OfficeCode != "" {
+			start := time.Now()
 			today := todayStr()
 			toDate := dateAfterDays(7)
-			meals, err := fetchMeals(apiKey, s.OfficeCode, s.SchoolCode, today, toDate)
+			cacheKey := fmt.Sprintf("meals|%s|%s|%s|%s", s.OfficeCode, s.SchoolCode, today, toDate)
+			r, err := cachedFetch(&a.sources, ctx, cacheKey, func(ctx context.Context) (r neisResult[[]MealData], err error) {
+				r.key, err = a.keyRing.call(keys, time.Now(), func(apiKey string) (err error) {
+					r.data, err = fetchMeals(ctx, apiKey, s.OfficeCode, s.SchoolCode, today, toDate)
+					return err
+				})
+				return r, err
+			})
 			if err != nil {
 				runtime.LogError(a.ctx, "Meals fetch error: "+err.Error())
 			}
+			meals := r.data
+			if meals == nil {
+				meals = []MealData{}
+			}
 			mu.Lock()
 			result.Meals = meals
 			mu.Unlock()
+			reportNEIS("meals", start, r.key, err)
+			emit("meals", meals)
 		} else {
-			runtime.LogWarning(a.ctx, fmt.Sprintf("Meals skipped: apiKey=%v, schoolCode=%q, officeCode=%q", apiKey != "", s.SchoolCode, s.OfficeCode))
+			runtime.LogWarning(a.ctx, fmt.Sprintf("Meals skipped: apiKey=%v, schoolCode=%q, officeCode=%q", len(keys) > 0, s.SchoolCode, s.OfficeCode))
+			skip("meals")
 		}
 	}()
 
 	// NEIS events
-	var neisEvents []ScheduleEvent
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		if apiKey != "" && s.SchoolCode != "" && s.OfficeCode != "" {
-			today := todayStr()
-			eventEnd := endOfMonthPlus2()
-			evts, err := fetchSchoolEvents(apiKey, s.OfficeCode, s.SchoolCode, today, eventEnd)
+		if len(keys) > 0 && s.SchoolCode != "" && s.OfficeCode != "" {
+			start := time.Now()
+			r, err := a.loadNEISEvents(ctx, s, keys)
 			if err != nil {
 				runtime.LogError(a.ctx, "Events fetch error: "+err.Error())
 			}
-			mu.Lock()
-			neisEvents = evts
-			mu.Unlock()
+			reportNEIS("neisEvents", start, r.key, err)
+			setEvents(&neisEvents, r.data)
 		} else {
-			runtime.LogWarning(a.ctx, fmt.Sprintf("Events skipped: apiKey=%v, schoolCode=%q, officeCode=%q", apiKey != "", s.SchoolCode, s.OfficeCode))
+			runtime.LogWarning(a.ctx, fmt.Sprintf("Events skipped: apiKey=%v, schoolCode=%q, officeCode=%q", len(keys) > 0, s.SchoolCode, s.OfficeCode))
+			skip("neisEvents")
 		}
 	}()
 
@@ -139,23 +498,48 @@ func (a *App) FetchDashboardData() DashboardData {
 	go func() {
 		defer wg.Done()
 		if s.SpreadsheetURL != "" {
-			tt, _ := fetchTimetableFromSheet(s.SpreadsheetURL)
+			start := time.Now()
+			tt, err := cachedFetch(&a.sources, ctx, "timetable|"+s.SpreadsheetURL, func(ctx context.Context) (*TimetableData, error) {
+				return fetchTimetableFromSheet(ctx, s.SpreadsheetURL)
+			})
 			mu.Lock()
 			result.Timetable = tt
 			mu.Unlock()
+			report("timetable", start, err)
+			emit("timetable", tt)
+		} else {
+			skip("timetable")
 		}
 	}()
 
 	// Sheet events
-	var sheetEvents []ScheduleEvent
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		if s.SpreadsheetURL != "" {
-			evts, _ := fetchEventsFromSheet(s.SpreadsheetURL)
-			mu.Lock()
-			sheetEvents = evts
-			mu.Unlock()
+			start := time.Now()
+			evts, err := a.loadSheetEvents(ctx, s.SpreadsheetURL)
+			report("sheetEvents", start, err)
+			setEvents(&sheetEvents, evts)
+		} else {
+			skip("sheetEvents")
+		}
+	}()
+
+	// Calendar (.ics) events
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if len(s.ICSSources) > 0 {
+			start := time.Now()
+			evts, err := a.loadICSEvents(ctx, s.ICSSources)
+			if err != nil {
+				runtime.LogError(a.ctx, "ICS fetch error: "+err.Error())
+			}
+			report("ics", start, err)
+			setEvents(&icsEvents, evts)
+		} else {
+			skip("ics")
 		}
 	}()
 
@@ -164,28 +548,100 @@ func (a *App) FetchDashboardData() DashboardData {
 	go func() {
 		defer wg.Done()
 		if s.SpreadsheetURL != "" {
-			sp, _ := fetchStudyPlanFromSheet(s.SpreadsheetURL)
+			start := time.Now()
+			sp, err := cachedFetch(&a.sources, ctx, "studyPlan|"+s.SpreadsheetURL, func(ctx context.Context) (*StudyPlanResult, error) {
+				return fetchStudyPlanFromSheet(ctx, s.SpreadsheetURL)
+			})
 			mu.Lock()
 			result.StudyPlan = sp
 			mu.Unlock()
+			report("studyPlan", start, err)
+			emit("studyPlan", sp)
+		} else {
+			skip("studyPlan")
 		}
 	}()
 
 	wg.Wait()
 
-	// Merge and deduplicate events
-	result.Events = mergeEvents(neisEvents, sheetEvents)
+	// A cancelled fetch is partial; keep the state from the last full one.
+	if ctx.Err() != nil {
+		result.Canceled = true
+		return result
+	}
+
+	if result.Weather != nil {
+		a.background.setWeather(result.Weather)
+	}
 
-	// Ensure non-nil slices for JSON
+	// Keep the last good timetable for bells if the sheet fetch failed.
+	if result.Timetable != nil {
+		a.bells.setPeriods(result.Timetable.Periods)
+	} else if s.SpreadsheetURL == "" {
+		a.bells.setPeriods(nil)
+	}
+
+	// Merge and deduplicate events; non-nil slices for JSON
+	result.Events = mergedEvents()
 	if result.Meals == nil {
 		result.Meals = []MealData{}
 	}
-	if result.Events == nil {
-		result.Events = []ScheduleEvent{}
-	}
 	return result
 }
 
+// The event sources below are shared by the dashboard and ExportCalendar.
+// Each covers today to the end of the month after next.
+
+func (a *App) loadNEISEvents(ctx context.Context, s Settings, keys []neisKey) (neisResult[[]ScheduleEvent], error) {
+	today, eventEnd := todayStr(), endOfMonthPlus2()
+	cacheKey := fmt.Sprintf("neisEvents|%s|%s|%s|%s", s.OfficeCode, s.SchoolCode, today, eventEnd)
+	return cachedFetch(&a.sources, ctx, cacheKey, func(ctx context.Context) (r neisResult[[]ScheduleEvent], err error) {
+		r.key, err = a.keyRing.call(keys, time.Now(), func(apiKey string) (err error) {
+			r.data, err = fetchSchoolEvents(ctx, apiKey, s.OfficeCode, s.SchoolCode, today, eventEnd)
+			return err
+		})
+		return r, err
+	})
+}
+
+func (a *App) loadSheetEvents(ctx context.Context, spreadsheetURL string) ([]ScheduleEvent, error) {
+	return cachedFetch(&a.sources, ctx, "sheetEvents|"+spreadsheetURL, func(ctx context.Context) ([]ScheduleEvent, error) {
+		return fetchEventsFromSheet(ctx, spreadsheetURL)
+	})
+}
+
+func (a *App) loadICSEvents(ctx context.Context, sources []string) ([]ScheduleEvent, error) {
+	from, to := todayStr(), endOfMonthPlus2()
+	key := fmt.Sprintf("ics|%s|%s|%s", strings.Join(sources, "\n"), from, to)
+	return cachedFetch(&a.sources, ctx, key, func(ctx context.Context) ([]ScheduleEvent, error) {
+		events, notes, err := fetchICSEvents(ctx, sources, from, to)
+		for _, note := range notes {
+			runtime.LogWarning(a.ctx, note)
+		}
+		return events, err
+	})
+}
+
+func (a *App) dashboardContext() context.Context {
+	a.fetchMu.Lock()
+	defer a.fetchMu.Unlock()
+	if a.fetchCtx == nil {
+		a.fetchCtx, a.cancelFetch = context.WithCancel(a.ctx)
+	}
+	return a.fetchCtx
+}
+
+// CancelDashboardFetch abandons every FetchDashboardData in flight. They
+// return at once with Canceled set.
+func (a *App) CancelDashboardFetch() {
+	a.fetchMu.Lock()
+	defer a.fetchMu.Unlock()
+	if a.cancelFetch != nil {
+		a.cancelFetch()
+		a.fetchCtx, a.cancelFetch = nil, nil
+	}
+}
+
 // ===== School Search =====
 
 type SchoolSearchResult struct {
@@ -194,14 +650,18 @@ type SchoolSearchResult struct {
 }
 
 func (a *App) SearchSchool(name string) SchoolSearchResult {
-	apiKey := a.getEffectiveAPIKey()
-	if apiKey == "" {
+	keys := a.neisKeys(loadSettings())
+	if len(keys) == 0 {
 		return SchoolSearchResult{Error: "NEIS API 키가 설정되지 않았습니다. 설정에서 개인 인증키를 입력해 주세요."}
 	}
 	if name == "" {
 		return SchoolSearchResult{Schools: []SchoolInfo{}}
 	}
-	results, err := searchSchool(apiKey, name)
+	var results []SchoolInfo
+	_, err := a.keyRing.call(keys, time.Now(), func(apiKey string) (err error) {
+		results, err = searchSchool(a.ctx, apiKey, name)
+		return err
+	})
 	if err != nil {
 		runtime.LogError(a.ctx, "School search error: "+err.Error())
 		return SchoolSearchResult{Error: err.Error()}
@@ -212,6 +672,32 @@ func (a *App) SearchSchool(name string) SchoolSearchResult {
 	return SchoolSearchResult{Schools: results}
 }
 
+// ===== NEIS API Key =====
+
+// ValidateAPIKey checks a personal NEIS key with a cheap NEIS call. The
+// masked key from GetSettings stands for the saved key.
+func (a *App) ValidateAPIKey(key string) APIKeyValidation {
+	key = strings.TrimSpace(key)
+	if saved := loadSettings().CustomAPIKey; saved != "" && key == maskSecret(saved) {
+		key = saved
+	}
+	if key == "" {
+		return APIKeyValidation{Status: "invalid", Message: "인증키를 입력하세요"}
+	}
+	v := validateAPIKey(a.ctx, key)
+	if v.Status == "valid" {
+		a.keyRing.clear(key)
+	}
+	return v
+}
+
+// GetAPIUsage reports today's NEIS calls with the key in use, which is the
+// fallback key while the preferred one is failing.
+func (a *App) GetAPIUsage() APIUsage {
+	key := a.keyRing.active(a.neisKeys(loadSettings()), time.Now())
+	return neisUsageFor(key.Value, key.Label == neisKeyPersonal)
+}
+
 // ===== Geocoding =====
 
 type Coords struct {
@@ -220,20 +706,388 @@ type Coords struct {
 }
 
 func (a *App) GeocodeAddress(addr string) *Coords {
-	c, err := geocodeAddress(addr)
+	c, err := geocodeAddress(a.ctx, addr)
 	if err != nil || c == nil {
 		return nil
 	}
 	return c
 }
 
-// ===== Alarm File Picker =====
+// ===== Personal Events =====
+
+type PersonalEventResult struct {
+	Event PersonalEvent `json:"event"`
+	Error string        `json:"error"`
+}
+
+func (a *App) GetPersonalEvents() []PersonalEvent {
+	return loadPersonalEvents()
+}
+
+func (a *App) AddPersonalEvent(e PersonalEvent) PersonalEventResult {
+	if !a.admin.allowed(loadSettings().AdminPINHash, time.Now()) {
+		return PersonalEventResult{Event: e, Error: pinRequiredMessage}
+	}
+	saved, err := addPersonalEvent(e)
+	if err != nil {
+		return PersonalEventResult{Event: e, Error: err.Error()}
+	}
+	a.personalEventsChanged()
+	return PersonalEventResult{Event: saved}
+}
+
+func (a *App) UpdatePersonalEvent(e PersonalEvent) PersonalEventResult {
+	if !a.admin.allowed(loadSettings().AdminPINHash, time.Now()) {
+		return PersonalEventResult{Event: e, Error: pinRequiredMessage}
+	}
+	saved, err := updatePersonalEvent(e)
+	if err != nil {
+		return PersonalEventResult{Event: e, Error: err.Error()}
+	}
+	a.personalEventsChanged()
+	return PersonalEventResult{Event: saved}
+}
+
+// DeletePersonalEvent returns an empty string on success, or an error message.
+func (a *App) DeletePersonalEvent(id string) string {
+	if !a.admin.allowed(loadSettings().AdminPINHash, time.Now()) {
+		return pinRequiredMessage
+	}
+	if err := deletePersonalEvent(id); err != nil {
+		runtime.LogError(a.ctx, "Failed to delete personal event: "+err.Error())
+		return "일정 삭제 실패: " + err.Error()
+	}
+	a.personalEventsChanged()
+	return ""
+}
+
+func (a *App) personalEventsChanged() {
+	a.notifyRemindersChanged()
+	runtime.EventsEmit(a.ctx, "personalEventsChanged")
+}
+
+// ===== Bells =====
+
+// GetTodayBells returns every bell scheduled for today under the current
+// bell rules, including extra fixed-time bells.
+func (a *App) GetTodayBells() []Bell {
+	bells := a.bells.today(loadSettings().Bells, time.Now())
+	if bells == nil {
+		bells = []Bell{}
+	}
+	return bells
+}
+
+func (a *App) GetBellMuteStatus() BellMuteStatus {
+	return a.bellMuteStatus(loadSettings(), time.Now())
+}
+
+// SetBellsMutedToday turns the manual "no bells today" override on or off.
+// It resets by itself at midnight, so it doesn't need the admin PIN.
+func (a *App) SetBellsMutedToday(muted bool) {
+	s, err := updateSettings(func(s *Settings) bool {
+		if muted {
+			s.MuteBellsDate = todayStr()
+		} else {
+			s.MuteBellsDate = ""
+		}
+		return true
+	})
+	if err != nil {
+		runtime.LogError(a.ctx, "Failed to save settings: "+err.Error())
+	}
+	a.syncTrayMute(muted)
+	runtime.EventsEmit(a.ctx, "bellMuteChanged", a.bellMuteStatus(s, time.Now()))
+}
+
+// ===== D-day Countdowns =====
+
+// GetCountdowns returns every pinned D-day target with the remaining calendar
+// and school days. Targets whose date has passed are removed.
+func (a *App) GetCountdowns() []CountdownStatus {
+	today := todayStr()
+	s, err := updateSettings(func(s *Settings) bool {
+		kept, pruned := pruneCountdowns(s.Countdowns, today)
+		s.Countdowns = kept
+		return pruned
+	})
+	if err != nil {
+		runtime.LogError(a.ctx, "Failed to save settings: "+err.Error())
+	}
+	if len(s.Countdowns) == 0 {
+		return []CountdownStatus{}
+	}
+
+	last := today
+	for _, c := range s.Countdowns {
+		if c.Date > last {
+			last = c.Date
+		}
+	}
+	start, _ := time.ParseInLocation("20060102", today, time.Local)
+	return countdownStatuses(s.Countdowns, start, a.schoolHolidays(today, last))
+}
+
+// AddCountdown pins a custom date. Returns an error message, or "" on success.
+func (a *App) AddCountdown(name, date string) string {
+	return a.addCountdown(name, date, "")
+}
+
+// PinEventCountdown pins an event from the merged event list.
+func (a *App) PinEventCountdown(e ScheduleEvent) string {
+	return a.addCountdown(e.Name, e.Date, e.Source)
+}
+
+func (a *App) addCountdown(name, date, source string) string {
+	c, err := newCountdown(name, date, source)
+	if err != nil {
+		return err.Error()
+	}
+	var msg string
+	_, err = updateSettings(func(s *Settings) bool {
+		if !a.admin.allowed(s.AdminPINHash, time.Now()) {
+			msg = pinRequiredMessage
+			return false
+		}
+		s.Countdowns = append(s.Countdowns, c)
+		return true
+	})
+	if msg != "" {
+		return msg
+	}
+	if err != nil {
+		return "설정 저장 실패: " + err.Error()
+	}
+	runtime.EventsEmit(a.ctx, "countdownsChanged")
+	return ""
+}
+
+// RemoveCountdown returns an empty string on success, or an error message.
+func (a *App) RemoveCountdown(id string) string {
+	var msg string
+	_, err := updateSettings(func(s *Settings) bool {
+		if !a.admin.allowed(s.AdminPINHash, time.Now()) {
+			msg = pinRequiredMessage
+			return false
+		}
+		var remaining []Countdown
+		for _, c := range s.Countdowns {
+			if c.Id != id {
+				remaining = append(remaining, c)
+			}
+		}
+		s.Countdowns = remaining
+		return true
+	})
+	if msg != "" {
+		return msg
+	}
+	if err != nil {
+		runtime.LogError(a.ctx, "Failed to save settings: "+err.Error())
+		return "설정 저장 실패: " + err.Error()
+	}
+	runtime.EventsEmit(a.ctx, "countdownsChanged")
+	return ""
+}
+
+// ===== Calendar Export =====
+
+// ExportCalendar saves the merged NEIS + sheet + ICS event list as an .ics file,
+// optionally with the weekly timetable as recurring events. Unlike the
+// dashboard list it is not cut to the event limit, and personal events are
+// left out unless includePersonal is set.
+// Returns an empty string on success or cancel, or an error message.
+func (a *App) ExportCalendar(includeTimetable, includePersonal bool) string {
+	s := loadSettings()
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "학사일정 내보내기",
+		DefaultFilename: "wall-e-calendar.ics",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "iCalendar Files", Pattern: "*.ics"},
+		},
+	})
+	if err != nil {
+		return "저장 위치 선택 실패: " + err.Error()
+	}
+	if path == "" {
+		return ""
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".ics") {
+		path += ".ics"
+	}
+
+	events, err := a.exportEvents(a.ctx, s, includePersonal)
+	if err != nil {
+		return "일정을 불러오지 못했습니다: " + err.Error()
+	}
+	var tt *TimetableData
+	if includeTimetable && s.SpreadsheetURL != "" {
+		tt, err = cachedFetch(&a.sources, a.ctx, "timetable|"+s.SpreadsheetURL, func(ctx context.Context) (*TimetableData, error) {
+			return fetchTimetableFromSheet(ctx, s.SpreadsheetURL)
+		})
+		if err != nil {
+			return "시간표를 불러오지 못했습니다: " + err.Error()
+		}
+	}
+
+	calName := "학사일정"
+	if s.SchoolName != "" {
+		calName = s.SchoolName + " 학사일정"
+	}
+	ics := buildICS(calName, events, tt, time.Now())
+	if err := os.WriteFile(path, []byte(ics), 0644); err != nil {
+		return "파일 저장 실패: " + err.Error()
+	}
+	return ""
+}
+
+// exportEvents fetches every configured event source and merges them
+// without a limit. A source that fails fails the export, so the file is
+// never silently missing events.
+func (a *App) exportEvents(ctx context.Context, s Settings, includePersonal bool) ([]ScheduleEvent, error) {
+	var neisEvents, sheetEvents, icsEvents, personalEvents []ScheduleEvent
+	var errs [3]error
+	var wg sync.WaitGroup
+	if keys := a.neisKeys(s); len(keys) > 0 && s.SchoolCode != "" && s.OfficeCode != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r, err := a.loadNEISEvents(ctx, s, keys)
+			neisEvents, errs[0] = r.data, err
+		}()
+	}
+	if s.SpreadsheetURL != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sheetEvents, errs[1] = a.loadSheetEvents(ctx, s.SpreadsheetURL)
+		}()
+	}
+	if len(s.ICSSources) > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			icsEvents, errs[2] = a.loadICSEvents(ctx, s.ICSSources)
+		}()
+	}
+	if includePersonal {
+		personalEvents = personalScheduleEvents(loadPersonalEvents(), todayStr())
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs[:]...); err != nil {
+		return nil, err
+	}
+	return mergeEventsWithLimit(EventLimit{}, neisEvents, sheetEvents, icsEvents, personalEvents), nil
+}
+
+// ===== Settings Bundle =====
+
+// ExportSettings saves the settings with their custom backgrounds and sounds
+// as a zip that ImportSettings on other PCs can read. The API key and admin
+// PIN are left out unless includeSecrets is set, which needs the admin PIN.
+// Returns an empty string on success or cancel, or an error message.
+func (a *App) ExportSettings(includeSecrets bool) string {
+	if includeSecrets && !a.admin.allowed(loadSettings().AdminPINHash, time.Now()) {
+		return pinRequiredMessage
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "설정 내보내기",
+		DefaultFilename: "wall-e-settings.zip",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Zip Files", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return "저장 위치 선택 실패: " + err.Error()
+	}
+	if path == "" {
+		return ""
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".zip") {
+		path += ".zip"
+	}
+
+	var buf bytes.Buffer
+	if err := writeSettingsBundle(&buf, loadSettings(), includeSecrets); err != nil {
+		return "설정 내보내기 실패: " + err.Error()
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return "파일 저장 실패: " + err.Error()
+	}
+	return ""
+}
+
+// ImportSettings replaces the settings, backgrounds and sounds with a bundle
+// written by ExportSettings, keeping the local fields selected in opts.
+// Returns an empty string on success or cancel, or an error message.
+func (a *App) ImportSettings(opts ImportOptions) string {
+	if !a.admin.allowed(loadSettings().AdminPINHash, time.Now()) {
+		return pinRequiredMessage
+	}
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title: "설정 가져오기",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Zip Files", Pattern: "*.zip"},
+		},
+	})
+	if err != nil {
+		return "파일 선택 실패: " + err.Error()
+	}
+	if path == "" {
+		return ""
+	}
+
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return "설정 파일을 열 수 없습니다: " + err.Error()
+	}
+	defer zr.Close()
+
+	var msg string
+	var local Settings
+	s, err := updateSettings(func(s *Settings) bool {
+		if !a.admin.allowed(s.AdminPINHash, time.Now()) {
+			msg = pinRequiredMessage
+			return false
+		}
+		imported, err := readSettingsBundle(&zr.Reader, *s, opts)
+		if err != nil {
+			msg = "설정 가져오기 실패: " + err.Error()
+			return false
+		}
+		local, *s = *s, imported
+		return true
+	})
+	if msg != "" {
+		return msg
+	}
+	if err != nil {
+		runtime.LogError(a.ctx, "Failed to save settings: "+err.Error())
+		return "설정 저장 실패: " + err.Error()
+	}
+	pruneReplacedMedia(local, s)
+
+	a.bells.notify()
+	a.background.notify()
+	a.notifyHolidaysChanged()
+	runtime.EventsEmit(a.ctx, "settingsChanged")
+	if err := applyNetworkSettings(loadSettings()); err != nil {
+		return "설정을 가져왔지만 네트워크 설정을 적용하지 못했습니다: " + err.Error()
+	}
+	return ""
+}
+
+// ===== Alarm Sounds =====
 
 type AlarmFileResult struct {
-	Data string `json:"data"`
-	Name string `json:"name"`
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	FileName string `json:"fileName"`
 }
 
+// PickAlarmFile copies an audio file into the sounds folder. The caller adds
+// the result to Settings.CustomSounds and saves.
 func (a *App) PickAlarmFile() *AlarmFileResult {
 	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
 		Title: "알림음 파일 선택",
@@ -250,37 +1104,67 @@ func (a *App) PickAlarmFile() *AlarmFileResult {
 		return nil
 	}
 
-	name := filepath.Base(path)
-	ext := strings.ToLower(filepath.Ext(path))
-	mimeType := mime.TypeByExtension(ext)
-	if mimeType == "" {
-		mimeMap := map[string]string{
-			".mp3":  "audio/mpeg",
-			".wav":  "audio/wav",
-			".ogg":  "audio/ogg",
-			".m4a":  "audio/mp4",
-			".webm": "audio/webm",
-		}
-		mimeType = mimeMap[ext]
-		if mimeType == "" {
-			mimeType = "audio/mpeg"
-		}
+	sound, err := writeSoundFile(filepath.Base(path), filepath.Ext(path), data)
+	if err != nil {
+		return nil
 	}
 
-	b64 := base64.StdEncoding.EncodeToString(data)
-	dataURL := fmt.Sprintf("data:%s;base64,%s", mimeType, b64)
+	return &AlarmFileResult{
+		Id:       sound.Id,
+		Name:     sound.Name,
+		FileName: sound.FileName,
+	}
+}
 
-	return &AlarmFileResult{Data: dataURL, Name: name}
+// GetCustomSoundURL returns the asset server URL of an imported sound.
+func (a *App) GetCustomSoundURL(id string) string {
+	if _, ok := findCustomSound(loadSettings(), id); !ok {
+		return ""
+	}
+	return customSoundURL(id)
+}
+
+// RemoveCustomSound returns an empty string on success, or an error message.
+func (a *App) RemoveCustomSound(id string) string {
+	var msg string
+	var removed CustomSound
+	var ok bool
+	_, err := updateSettings(func(s *Settings) bool {
+		if !a.admin.allowed(s.AdminPINHash, time.Now()) {
+			msg = pinRequiredMessage
+			return false
+		}
+		removed, ok = removeCustomSound(s, id)
+		return ok
+	})
+	if msg != "" {
+		return msg
+	}
+	// Only delete the file once settings no longer point at it.
+	if err != nil {
+		runtime.LogError(a.ctx, "Failed to save settings: "+err.Error())
+		return "설정 저장 실패: " + err.Error()
+	}
+	if !ok {
+		return ""
+	}
+	os.Remove(filepath.Join(soundsDir(), removed.FileName))
+	runtime.EventsEmit(a.ctx, "settingsChanged")
+	return ""
 }
 
 // ===== Custom Background =====
 
 type BackgroundFileResult struct {
-	Id       string `json:"id"`
-	Name     string `json:"name"`
-	FileName string `json:"fileName"`
+	Id            string `json:"id"`
+	Name          string `json:"name"`
+	FileName      string `json:"fileName"`
+	ThumbFileName string `json:"thumbFileName,omitempty"`
+	Hash          string `json:"hash,omitempty"`
 }
 
+// PickBackgroundFile imports an image: it is downscaled to the display
+// resolution with a thumbnail, and a photo imported before is reused.
 func (a *App) PickBackgroundFile() *BackgroundFileResult {
 	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
 		Title: "배경 이미지 선택",
@@ -297,86 +1181,141 @@ func (a *App) PickBackgroundFile() *BackgroundFileResult {
 		return nil
 	}
 
-	origName := filepath.Base(path)
-	ext := filepath.Ext(path)
-	id := uuid.New().String()
-	fileName := id + ext
-
-	bgDir := filepath.Join(settingsDir, "backgrounds")
-	if err := os.MkdirAll(bgDir, 0755); err != nil {
+	maxW, maxH := a.displaySize()
+	bg, _, err := importBackground(loadSettings().CustomBackgrounds, filepath.Base(path), strings.ToLower(filepath.Ext(path)), data, maxW, maxH)
+	if err != nil {
+		runtime.LogError(a.ctx, "Failed to import background: "+err.Error())
 		return nil
 	}
 
-	destPath := filepath.Join(bgDir, fileName)
-	if err := os.WriteFile(destPath, data, 0644); err != nil {
-		return nil
+	return &BackgroundFileResult{
+		Id:            bg.Id,
+		Name:          bg.Name,
+		FileName:      bg.FileName,
+		ThumbFileName: bg.ThumbFileName,
+		Hash:          bg.Hash,
 	}
+}
 
-	return &BackgroundFileResult{
-		Id:       id,
-		Name:     origName,
-		FileName: fileName,
+// displaySize returns the largest screen in physical pixels, so backgrounds
+// stay sharp on high-DPI displays.
+func (a *App) displaySize() (int, int) {
+	w, h := 0, 0
+	screens, err := runtime.ScreenGetAll(a.ctx)
+	if err == nil {
+		for _, sc := range screens {
+			size := sc.PhysicalSize
+			if size.Width == 0 {
+				size = sc.Size
+			}
+			w, h = max(w, size.Width), max(h, size.Height)
+		}
+	}
+	if w == 0 || h == 0 {
+		return defaultDisplayWidth, defaultDisplayHeight
 	}
+	return w, h
 }
 
+// GetCustomBackgroundURL returns the asset server URL of an imported background.
 func (a *App) GetCustomBackgroundURL(id string) string {
-	s := loadSettings()
-	for _, bg := range s.CustomBackgrounds {
+	for _, bg := range loadSettings().CustomBackgrounds {
 		if bg.Id == id {
-			bgPath := filepath.Join(settingsDir, "backgrounds", bg.FileName)
-			data, err := os.ReadFile(bgPath)
-			if err != nil {
-				return ""
-			}
+			return customBackgroundURL(id)
+		}
+	}
+	return ""
+}
 
-			ext := strings.ToLower(filepath.Ext(bg.FileName))
-			mimeType := mime.TypeByExtension(ext)
-			if mimeType == "" {
-				mimeMap := map[string]string{
-					".jpg":  "image/jpeg",
-					".jpeg": "image/jpeg",
-					".png":  "image/png",
-					".webp": "image/webp",
-					".bmp":  "image/bmp",
-				}
-				mimeType = mimeMap[ext]
-				if mimeType == "" {
-					mimeType = "image/jpeg"
-				}
-			}
+// GetActiveBackground returns the background the rotation policy shows now.
+func (a *App) GetActiveBackground() string {
+	return a.background.pick(loadSettings(), time.Now())
+}
 
-			b64 := base64.StdEncoding.EncodeToString(data)
-			return fmt.Sprintf("data:%s;base64,%s", mimeType, b64)
+// GetCustomBackgroundThumbURL returns the thumbnail URL of an imported
+// background, or the full image for backgrounds imported without one.
+func (a *App) GetCustomBackgroundThumbURL(id string) string {
+	for _, bg := range loadSettings().CustomBackgrounds {
+		if bg.Id == id {
+			if bg.ThumbFileName != "" {
+				return customThumbURL(id)
+			}
+			return customBackgroundURL(id)
 		}
 	}
 	return ""
 }
 
-func (a *App) RemoveCustomBackground(id string) {
-	s := loadSettings()
-
+// RemoveCustomBackground returns an empty string on success, or an error
+// message.
+func (a *App) RemoveCustomBackground(id string) string {
+	var msg string
 	var removed *CustomBackground
-	var remaining []CustomBackground
-	for _, bg := range s.CustomBackgrounds {
-		if bg.Id == id {
-			bgCopy := bg
-			removed = &bgCopy
-		} else {
-			remaining = append(remaining, bg)
+	_, err := updateSettings(func(s *Settings) bool {
+		if !a.admin.allowed(s.AdminPINHash, time.Now()) {
+			msg = pinRequiredMessage
+			return false
+		}
+		var remaining []CustomBackground
+		for _, bg := range s.CustomBackgrounds {
+			if bg.Id == id {
+				bgCopy := bg
+				removed = &bgCopy
+			} else {
+				remaining = append(remaining, bg)
+			}
 		}
+		s.CustomBackgrounds = remaining
+		clearBackgroundRefs(s, id)
+		return true
+	})
+	if msg != "" {
+		return msg
+	}
+	if err != nil {
+		runtime.LogError(a.ctx, "Failed to save settings: "+err.Error())
+		return "설정 저장 실패: " + err.Error()
 	}
 
+	// Only delete the files once settings no longer point at them.
 	if removed != nil {
-		bgPath := filepath.Join(settingsDir, "backgrounds", removed.FileName)
-		os.Remove(bgPath)
+		os.Remove(filepath.Join(backgroundsDir(), removed.FileName))
+		if removed.ThumbFileName != "" {
+			os.Remove(filepath.Join(backgroundThumbsDir(), removed.ThumbFileName))
+		}
 	}
+	runtime.EventsEmit(a.ctx, "settingsChanged")
+	return ""
+}
 
-	s.CustomBackgrounds = remaining
-	if s.BackgroundID == "custom:"+id {
-		s.BackgroundID = ""
+// CleanupBackgrounds reconciles the backgrounds folder with the saved
+// entries. Orphan images are re-adopted, or deleted when deleteOrphans is set.
+func (a *App) CleanupBackgrounds(deleteOrphans bool) BackgroundMaintenanceReport {
+	if !a.admin.allowed(loadSettings().AdminPINHash, time.Now()) {
+		return BackgroundMaintenanceReport{Errors: []string{pinRequiredMessage}}
 	}
-	saveSettings(s)
-	runtime.EventsEmit(a.ctx, "settingsChanged")
+	report := a.maintainBackgrounds(deleteOrphans)
+	if report.settingsChanged {
+		runtime.EventsEmit(a.ctx, "settingsChanged")
+	}
+	return report
+}
+
+// maintainBackgrounds runs reconcileBackgrounds and saves the result. At
+// startup it runs with deleteOrphans off, so nothing the user imported is lost.
+func (a *App) maintainBackgrounds(deleteOrphans bool) BackgroundMaintenanceReport {
+	var report BackgroundMaintenanceReport
+	_, err := updateSettings(func(s *Settings) bool {
+		report = reconcileBackgrounds(s, deleteOrphans)
+		return report.settingsChanged
+	})
+	for _, e := range report.Errors {
+		runtime.LogWarning(a.ctx, "Background maintenance: "+e)
+	}
+	if err != nil {
+		runtime.LogError(a.ctx, "Failed to save settings: "+err.Error())
+	}
+	return report
 }
 
 // ===== Auto Start =====
@@ -385,8 +1324,13 @@ func (a *App) GetAutoStart() bool {
 	return getAutoStartEnabled()
 }
 
-func (a *App) SetAutoStart(enabled bool) {
+// SetAutoStart returns an empty string on success, or an error message.
+func (a *App) SetAutoStart(enabled bool) string {
+	if !a.admin.allowed(loadSettings().AdminPINHash, time.Now()) {
+		return pinRequiredMessage
+	}
 	setAutoStart(enabled)
+	return ""
 }
 
 // ===== Window Controls =====
@@ -400,11 +1344,28 @@ func (a *App) MaximizeWindow() {
 }
 
 func (a *App) CloseWindow() {
+	if !a.requireAdmin("close") {
+		return
+	}
+	a.admin.lock()
 	runtime.WindowHide(a.ctx)
 }
 
-func (a *App) GetNeisAPIKey() string {
-	return a.neisAPIKey
+// beforeClose is the OnBeforeClose hook, so that Alt+F4 and closing from the
+// taskbar need the PIN like the close button. QuitApp has checked it already.
+func (a *App) beforeClose(ctx context.Context) (prevent bool) {
+	if a.quitting.Load() {
+		return false
+	}
+	return !a.requireAdmin("close")
+}
+
+// QuitApp quits the app, e.g. after the PIN prompt for a tray quit.
+func (a *App) QuitApp() {
+	if a.requireAdmin("quit") {
+		a.quitting.Store(true)
+		a.quitApp()
+	}
 }
 
 func (a *App) GetAppVersion() string {
@@ -422,12 +1383,19 @@ type UpdateCheckResult struct {
 }
 
 func (a *App) CheckForUpdate() UpdateCheckResult {
-	return checkForUpdate(appVersion)
+	return checkForUpdate(a.ctx, appVersion, loadSettings().UpdateChannel)
 }
 
 // DownloadAndRunUpdate downloads the setup exe and runs it silently.
 // Returns an empty string on success, or an error message.
 func (a *App) DownloadAndRunUpdate(url string) string {
+	s := loadSettings()
+	if !a.admin.allowed(s.AdminPINHash, time.Now()) {
+		return pinRequiredMessage
+	}
+	if s.UpdateChannel == updateChannelOff {
+		return updatesDisabledMessage
+	}
 	return downloadAndRunUpdate(a.ctx, url)
 }
 
@@ -437,32 +1405,148 @@ func (a *App) OpenDownloadURL(url string) {
 
 // ===== Helpers =====
 
-func mergeEvents(neis, sheet []ScheduleEvent) []ScheduleEvent {
-	all := append(neis, sheet...)
-	seen := make(map[string]bool)
-	var result []ScheduleEvent
+// EventLimit caps the merged event list by count and/or by a window of days
+// from today. A zero field means no limit on that dimension.
+type EventLimit struct {
+	MaxCount   int `json:"maxCount"`
+	WindowDays int `json:"windowDays"`
+}
+
+var defaultEventLimit = EventLimit{MaxCount: 30}
+
+// eventDetailPriority decides whose Detail text survives when the same event
+// comes from several sources: hand-written notes beat NEIS boilerplate.
+var eventDetailPriority = map[string]int{
+	"neis":     1,
+	"ics":      2,
+	"sheet":    3,
+	"personal": 4,
+}
+
+var (
+	eventTagPrefixRe      = regexp.MustCompile(`^\s*(?:\[([^\]]*)\]|\(([^)]*)\)|<([^>]*)>)\s*`)
+	eventSemesterPrefixRe = regexp.MustCompile(`^\s*(제\s*)?\d+\s*(학기|회)\s*`)
+)
 
-	for _, e := range all {
-		key := e.Date + "-" + e.Name
-		if !seen[key] {
-			seen[key] = true
-			result = append(result, e)
+// eventGenericTags are the bracketed source and category labels that are
+// dropped before comparing names. Any other bracket, such as "(1학년)" or
+// "[2반]", says who the event is for and stays part of the name.
+var eventGenericTags = map[string]bool{
+	"학교": true, "학사": true, "학사일정": true, "행사": true, "일정": true,
+	"공휴일": true, "휴일": true, "휴업일": true, "방학": true,
+	"안내": true, "공지": true, "알림": true, "기타": true, "중요": true,
+	"neis": true, "나이스": true, "교육청": true, "개인": true,
+}
+
+// stripEventTag drops one leading generic tag such as "[학교]".
+func stripEventTag(n string) string {
+	m := eventTagPrefixRe.FindStringSubmatch(n)
+	if m == nil || !eventGenericTags[strings.ToLower(strings.TrimSpace(m[1]+m[2]+m[3]))] {
+		return n
+	}
+	return n[len(m[0]):]
+}
+
+// normalizeEventName reduces a name to a dedup key: generic tags like "[학교]"
+// and "1학기"/"제3회" prefixes are dropped, as are whitespace and punctuation,
+// so "개학식", "개학식 " and "1학기 개학식" all compare equal.
+func normalizeEventName(name string) string {
+	n := name
+	for {
+		stripped := stripEventTag(n)
+		stripped = eventSemesterPrefixRe.ReplaceAllString(stripped, "")
+		if stripped == n {
+			break
+		}
+		n = stripped
+	}
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(n) {
+		if unicode.IsSpace(r) || unicode.IsPunct(r) || unicode.IsSymbol(r) {
+			continue
 		}
+		b.WriteRune(r)
+	}
+	if b.Len() == 0 {
+		return strings.TrimSpace(name)
 	}
+	return b.String()
+}
+
+// mergeEvents combines event lists from every source (NEIS, sheet, ICS, ...)
+// using the default limit.
+func mergeEvents(lists ...[]ScheduleEvent) []ScheduleEvent {
+	return mergeEventsWithLimit(defaultEventLimit, lists...)
+}
+
+// mergeEventsWithLimit dedups events on date + normalised name, records every
+// contributing source in Sources, sorts by date and applies the limit.
+// The first occurrence keeps its name; Detail follows eventDetailPriority.
+func mergeEventsWithLimit(limit EventLimit, lists ...[]ScheduleEvent) []ScheduleEvent {
+	index := make(map[string]int)
+	var result []ScheduleEvent
+	var detailRank []int
+
+	for _, list := range lists {
+		for _, e := range list {
+			key := e.Date + "-" + normalizeEventName(e.Name)
+			rank := eventDetailPriority[e.Source]
+
+			i, ok := index[key]
+			if !ok {
+				index[key] = len(result)
+				e.Sources = nil
+				if e.Source != "" {
+					e.Sources = []string{e.Source}
+				}
+				result = append(result, e)
+				detailRank = append(detailRank, rank)
+				continue
+			}
 
-	// Sort by date
-	for i := 0; i < len(result); i++ {
-		for j := i + 1; j < len(result); j++ {
-			if result[i].Date > result[j].Date {
-				result[i], result[j] = result[j], result[i]
+			existing := &result[i]
+			if e.Source != "" && !containsString(existing.Sources, e.Source) {
+				existing.Sources = append(existing.Sources, e.Source)
+			}
+			if e.Detail != "" && (existing.Detail == "" || rank > detailRank[i]) {
+				existing.Detail = e.Detail
+				detailRank[i] = rank
+			}
+			if existing.EndDate == "" && e.EndDate != "" {
+				existing.EndDate = e.EndDate
+			}
+			existing.Holiday = existing.Holiday || e.Holiday
+			if existing.UID == "" {
+				existing.UID = e.UID
 			}
 		}
 	}
 
-	// Limit to 30
-	if len(result) > 30 {
-		result = result[:30]
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Date < result[j].Date
+	})
+
+	if limit.WindowDays > 0 {
+		last := dateAfterDays(limit.WindowDays)
+		n := sort.Search(len(result), func(i int) bool {
+			return result[i].Date > last
+		})
+		result = result[:n]
+	}
+
+	if limit.MaxCount > 0 && len(result) > limit.MaxCount {
+		result = result[:limit.MaxCount]
 	}
 
 	return result
 }
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -1,7 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -10,6 +17,14 @@ func makeEvent(date, name, detail string) ScheduleEvent {
 	return ScheduleEvent{Date: date, Name: name, Detail: detail}
 }
 
+// withSource tags every event in the list with the given source.
+func withSource(source string, events ...ScheduleEvent) []ScheduleEvent {
+	for i := range events {
+		events[i].Source = source
+	}
+	return events
+}
+
 // --- mergeEvents: basic merge ---
 
 func TestMergeEvents_NeisAndSheetCombined(t *testing.T) {
@@ -32,21 +47,33 @@ func TestMergeEvents_NeisAndSheetCombined(t *testing.T) {
 
 func TestMergeEvents_DeduplicatesByDateAndName(t *testing.T) {
 	// The same date+name appears in both sources; only one copy should survive.
-	neis := []ScheduleEvent{
+	neis := withSource("neis",
 		makeEvent("20260301", "삼일절", "NEIS detail"),
-	}
-	sheet := []ScheduleEvent{
+	)
+	sheet := withSource("sheet",
 		makeEvent("20260301", "삼일절", "Sheet detail"),
-	}
+	)
 
 	got := mergeEvents(neis, sheet)
 
 	if len(got) != 1 {
 		t.Fatalf("expected 1 event after dedup, got %d", len(got))
 	}
-	// The NEIS event is appended first, so it should be the survivor.
-	if got[0].Detail != "NEIS detail" {
-		t.Errorf("expected surviving event to have detail %q, got %q", "NEIS detail", got[0].Detail)
+	// The sheet's detail text wins over NEIS.
+	if got[0].Detail != "Sheet detail" {
+		t.Errorf("expected surviving event to have detail %q, got %q", "Sheet detail", got[0].Detail)
+	}
+}
+
+func TestMergeEvents_DuplicateKeepsCalendarUID(t *testing.T) {
+	neis := withSource("neis", makeEvent("20260301", "삼일절", ""))
+	ics := withSource("ics", makeEvent("20260301", "삼일절", ""))
+	ics[0].UID = "holiday-0301@google.com"
+
+	got := mergeEvents(neis, ics)
+
+	if len(got) != 1 || got[0].UID != "holiday-0301@google.com" {
+		t.Errorf("expected the merged event to keep the ICS UID, got %+v", got)
 	}
 }
 
@@ -287,29 +314,171 @@ func TestMergeEvents_MixDuplicateAndUnique(t *testing.T) {
 	}
 }
 
-func TestMergeEvents_MixPreservesDetailFromFirstSeen(t *testing.T) {
-	// The NEIS entry is appended before the sheet entry, so its Detail wins.
-	neis := []ScheduleEvent{
+func TestMergeEvents_MixPrefersSheetDetail(t *testing.T) {
+	// The sheet's hand-written detail wins over NEIS even though NEIS comes first.
+	neis := withSource("neis",
 		makeEvent("20260301", "삼일절", "NEIS only"),
-	}
-	sheet := []ScheduleEvent{
+	)
+	sheet := withSource("sheet",
 		makeEvent("20260301", "삼일절", "Sheet override"),
 		makeEvent("20260401", "봄 소풍", "Sheet unique"),
-	}
+	)
 
 	got := mergeEvents(neis, sheet)
 
 	if len(got) != 2 {
 		t.Fatalf("expected 2 events, got %d", len(got))
 	}
-	if got[0].Detail != "NEIS only" {
-		t.Errorf("expected NEIS detail to survive dedup, got %q", got[0].Detail)
+	if got[0].Detail != "Sheet override" {
+		t.Errorf("expected sheet detail to win, got %q", got[0].Detail)
 	}
 	if got[1].Name != "봄 소풍" {
 		t.Errorf("expected sheet-unique event at index 1, got %q", got[1].Name)
 	}
 }
 
+func TestMergeEvents_EmptySheetDetailKeepsNeisDetail(t *testing.T) {
+	neis := withSource("neis", makeEvent("20260301", "삼일절", "NEIS only"))
+	sheet := withSource("sheet", makeEvent("20260301", "삼일절", ""))
+
+	got := mergeEvents(neis, sheet)
+
+	if len(got) != 1 || got[0].Detail != "NEIS only" {
+		t.Errorf("expected NEIS detail to be kept, got %+v", got)
+	}
+}
+
+// --- mergeEvents: fuzzy dedup ---
+
+func TestMergeEvents_FuzzyDedupWhitespaceAndPrefixes(t *testing.T) {
+	neis := withSource("neis", makeEvent("20260302", "개학식", ""))
+	sheet := withSource("sheet",
+		makeEvent("20260302", "개학식 ", ""),
+		makeEvent("20260302", "1학기 개학식", ""),
+		makeEvent("20260302", "[학교] 개 학 식!", ""),
+	)
+
+	got := mergeEvents(neis, sheet)
+
+	if len(got) != 1 {
+		t.Fatalf("expected 1 event after fuzzy dedup, got %d: %+v", len(got), got)
+	}
+	if got[0].Name != "개학식" {
+		t.Errorf("expected first-seen name to be kept, got %q", got[0].Name)
+	}
+}
+
+func TestMergeEvents_KeepsGradeQualifiedEventsApart(t *testing.T) {
+	sheet := withSource("sheet",
+		makeEvent("20261015", "(1학년) 현장체험학습", ""),
+		makeEvent("20261015", "(2학년) 현장체험학습", ""),
+	)
+
+	got := mergeEvents(sheet)
+
+	if len(got) != 2 {
+		t.Fatalf("expected both grades' events, got %d: %+v", len(got), got)
+	}
+}
+
+func TestMergeEvents_RecordsAllSources(t *testing.T) {
+	neis := withSource("neis", makeEvent("20260302", "개학식", ""))
+	sheet := withSource("sheet", makeEvent("20260302", "개학식", ""), makeEvent("20260303", "입학식", ""))
+	ics := withSource("ics", makeEvent("20260302", "개학식", ""))
+
+	got := mergeEvents(neis, sheet, ics)
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(got))
+	}
+	if fmt.Sprint(got[0].Sources) != "[neis sheet ics]" {
+		t.Errorf("Sources: got %v", got[0].Sources)
+	}
+	if got[0].Source != "neis" {
+		t.Errorf("Source: got %q, want neis", got[0].Source)
+	}
+	if fmt.Sprint(got[1].Sources) != "[sheet]" {
+		t.Errorf("Sources: got %v", got[1].Sources)
+	}
+}
+
+func TestNormalizeEventName(t *testing.T) {
+	cases := map[string]string{
+		"개학식":          "개학식",
+		"  개학식  ":      "개학식",
+		"1학기 개학식":      "개학식",
+		"제3회 학예회":      "학예회",
+		"(행사) 현장 체험학습": "현장체험학습",
+		"3학년 수학여행":     "3학년수학여행",
+		"(1학년) 현장체험학습": "1학년현장체험학습",
+		"[2반] 현장체험학습":  "2반현장체험학습",
+		"!!!":          "!!!",
+	}
+	for in, want := range cases {
+		if got := normalizeEventName(in); got != want {
+			t.Errorf("normalizeEventName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+// --- mergeEventsWithLimit ---
+
+func TestMergeEventsWithLimit_ZeroMeansUnlimited(t *testing.T) {
+	var neis []ScheduleEvent
+	for i := 0; i < 40; i++ {
+		neis = append(neis, makeEvent(fmt.Sprintf("2026%02d01", i+1), fmt.Sprintf("행사%d", i), ""))
+	}
+
+	got := mergeEventsWithLimit(EventLimit{}, neis)
+
+	if len(got) != 40 {
+		t.Fatalf("expected all 40 events, got %d", len(got))
+	}
+}
+
+func TestMergeEventsWithLimit_CustomCount(t *testing.T) {
+	neis := []ScheduleEvent{
+		makeEvent("20260301", "a", ""),
+		makeEvent("20260302", "b", ""),
+		makeEvent("20260303", "c", ""),
+	}
+
+	got := mergeEventsWithLimit(EventLimit{MaxCount: 2}, neis)
+
+	if len(got) != 2 || got[1].Date != "20260302" {
+		t.Errorf("expected the 2 earliest events, got %+v", got)
+	}
+}
+
+func TestMergeEventsWithLimit_WindowDays(t *testing.T) {
+	neis := []ScheduleEvent{
+		makeEvent(dateAfterDays(0), "오늘", ""),
+		makeEvent(dateAfterDays(7), "일주일 뒤", ""),
+		makeEvent(dateAfterDays(8), "8일 뒤", ""),
+	}
+
+	got := mergeEventsWithLimit(EventLimit{WindowDays: 7}, neis)
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events inside a 7-day window, got %d", len(got))
+	}
+	if got[1].Name != "일주일 뒤" {
+		t.Errorf("window should be inclusive of the last day, got %+v", got)
+	}
+}
+
+func TestMergeEvents_DoesNotModifyInputSlices(t *testing.T) {
+	neis := make([]ScheduleEvent, 1, 4)
+	neis[0] = makeEvent("20260301", "a", "")
+	sheet := []ScheduleEvent{makeEvent("20260302", "b", "")}
+
+	mergeEvents(neis, sheet)
+
+	if neis[:cap(neis)][1].Name != "" {
+		t.Error("mergeEvents must not append into the caller's backing array")
+	}
+}
+
 func TestMergeEvents_MixWithLargeInput(t *testing.T) {
 	// 20 unique NEIS + 20 unique sheet + 5 shared = 40 unique - 5 dedup = 35 unique,
 	// which exceeds 30, so result must be capped.
@@ -347,3 +516,183 @@ func TestMergeEvents_MixWithLargeInput(t *testing.T) {
 		}
 	}
 }
+
+// ============================================================
+// fetchDashboard
+// ============================================================
+
+func TestFetchDashboard_ReportsSectionsAsTheyArrive(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	today := todayStr()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/mealServiceDietInfo"):
+			fmt.Fprintf(w, `{"mealServiceDietInfo":[{"head":[]},{"row":[{"MLSV_YMD":"%s","DDISH_NM":"밥<br/>국"}]}]}`, today)
+		case strings.HasSuffix(r.URL.Path, "/SchoolSchedule"):
+			fmt.Fprintf(w, `{"SchoolSchedule":[{"head":[]},{"row":[{"AA_YMD":"%s","EVENT_NM":"체육대회"}]}]}`, today)
+		}
+	}))
+	defer srv.Close()
+	old := neisBaseURL
+	neisBaseURL = srv.URL
+	defer func() { neisBaseURL = old }()
+
+	ics := filepath.Join(t.TempDir(), "class.ics")
+	if err := os.WriteFile(ics, []byte(wrapICS("DTSTART;VALUE=DATE:"+today+"\nSUMMARY:학부모 상담")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := defaultSettings
+	s.SchoolCode, s.OfficeCode = "7010057", "B10"
+	s.ICSSources = []string{ics}
+	if err := saveSettings(s); err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	sections := map[string][]any{}
+	a := NewApp("built")
+	data := a.fetchDashboard(context.Background(), func(name string, value any) {
+		mu.Lock()
+		sections[name] = append(sections[name], value)
+		mu.Unlock()
+	})
+
+	if meals := sections["meals"]; len(meals) != 1 || len(meals[0].([]MealData)) != 1 {
+		t.Errorf("meals sections = %v", meals)
+	}
+	events := sections["events"]
+	if len(events) != 2 {
+		t.Fatalf("events sent %d times, want once per event source", len(events))
+	}
+	if last := events[1].([]ScheduleEvent); len(last) != 2 {
+		t.Errorf("last events section = %+v, want both sources merged", last)
+	}
+	for _, name := range []string{"weather", "airQuality", "timetable", "studyPlan"} {
+		if _, ok := sections[name]; ok {
+			t.Errorf("skipped section %q was sent", name)
+		}
+	}
+
+	if len(data.Meals) != 1 || len(data.Events) != 2 {
+		t.Errorf("data has %d meals, %d events", len(data.Meals), len(data.Events))
+	}
+	if st := data.Sources["weather"]; st.Status != "skipped" {
+		t.Errorf("weather = %+v, want skipped", st)
+	}
+	if st := data.Sources["meals"]; st.Status != "ok" || st.Key == nil || st.Key.Label != neisKeyBuiltin {
+		t.Errorf("meals = %+v, want ok with the builtin key", st)
+	}
+	if st := data.Sources["ics"]; st.Status != "ok" {
+		t.Errorf("ics = %+v", st)
+	}
+}
+
+func TestExportEvents_UnlimitedAndPersonalOptIn(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	today := todayStr()
+	ics := filepath.Join(t.TempDir(), "school.ics")
+	text := wrapICS("DTSTART;VALUE=DATE:" + today + "\nRRULE:FREQ=DAILY;COUNT=40\nSUMMARY:방과후 수업")
+	if err := os.WriteFile(ics, []byte(text), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := defaultSettings
+	s.ICSSources = []string{ics}
+	if _, err := addPersonalEvent(PersonalEvent{Date: today, Name: "학부모 상담"}); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewApp("built")
+	events, err := a.exportEvents(context.Background(), s, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 40 {
+		t.Errorf("got %d events, want all 40 despite the display limit", len(events))
+	}
+	for _, e := range events {
+		if e.Source == "personal" {
+			t.Errorf("personal event %q exported without opting in", e.Name)
+		}
+	}
+
+	events, err = a.exportEvents(context.Background(), s, true)
+	if err != nil || len(events) != 41 {
+		t.Errorf("with personal events: %d events, %v; want 41", len(events), err)
+	}
+}
+
+func TestExportEvents_FailedSourceFailsExport(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	s := defaultSettings
+	s.ICSSources = []string{filepath.Join(t.TempDir(), "missing.ics")}
+	if _, err := NewApp("built").exportEvents(context.Background(), s, false); err == nil {
+		t.Error("want an error for an unreadable calendar")
+	}
+}
+
+func TestGetDefaultSettings_KeepsBellsAndEventLimit(t *testing.T) {
+	s := NewApp("built").GetDefaultSettings()
+	if !s.Bells.Start.Enabled || !s.Bells.End.Enabled {
+		t.Errorf("bells = %+v, want the default bells enabled", s.Bells)
+	}
+	if s.EventLimit != 30 || !s.AlarmEnabled {
+		t.Errorf("eventLimit = %d, alarmEnabled = %v", s.EventLimit, s.AlarmEnabled)
+	}
+}
+
+func TestKeepBackendFields_StaleWindowDoesNotUndoBackendChanges(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	if err := os.MkdirAll(backgroundsDir(), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(backgroundsDir(), "new.jpg"), []byte("img"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	// Since the window loaded: the tray muted the bells, a countdown was
+	// pruned and cleanup dropped "gone" and adopted "orphan".
+	current := Settings{
+		MuteBellsDate:     "20261018",
+		Countdowns:        []Countdown{{Id: "c2", Date: "20261231"}},
+		CustomBackgrounds: []CustomBackground{{Id: "orphan", FileName: "orphan.jpg"}},
+	}
+	window := Settings{
+		SchoolName:   "새학교",
+		Countdowns:   []Countdown{{Id: "c1", Date: "20261001"}, {Id: "c2", Date: "20261231"}},
+		BackgroundID: "custom:gone",
+		CustomBackgrounds: []CustomBackground{
+			{Id: "gone", FileName: "gone.jpg"},
+			{Id: "new", FileName: "new.jpg"}, // imported in the window
+		},
+	}
+
+	keepBackendFields(&window, current)
+
+	if window.SchoolName != "새학교" {
+		t.Errorf("form field lost: %q", window.SchoolName)
+	}
+	if window.MuteBellsDate != "20261018" {
+		t.Errorf("MuteBellsDate: got %q, want the tray's mute", window.MuteBellsDate)
+	}
+	if len(window.Countdowns) != 1 || window.Countdowns[0].Id != "c2" {
+		t.Errorf("Countdowns: got %+v, want the pruned list", window.Countdowns)
+	}
+	var ids []string
+	for _, bg := range window.CustomBackgrounds {
+		ids = append(ids, bg.Id)
+	}
+	if strings.Join(ids, ",") != "orphan,new" {
+		t.Errorf("CustomBackgrounds: got %v, want [orphan new]", ids)
+	}
+	if window.BackgroundID != "" {
+		t.Errorf("BackgroundID still points at a dropped background: %q", window.BackgroundID)
+	}
+}
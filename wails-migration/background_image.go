@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"golang.org/x/image/draw"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
+)
+
+// Imported backgrounds are re-encoded at display resolution, because phone
+// photos of 20 MB or 6000px make the dashboard slow to paint.
+const (
+	// backgroundThumbWidth matches the bundled frontend/public/assets/bg/thumbs.
+	backgroundThumbWidth  = 200
+	backgroundJPEGQuality = 90
+
+	defaultDisplayWidth  = 1920
+	defaultDisplayHeight = 1080
+)
+
+type processedBackground struct {
+	Image []byte
+	Ext   string // ".jpg", or ".png" for images with transparency
+	Thumb []byte // JPEG, backgroundThumbWidth wide
+}
+
+func backgroundThumbsDir() string {
+	return filepath.Join(backgroundsDir(), "thumbs")
+}
+
+func contentHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// processBackgroundImage decodes an image, applies its EXIF orientation and
+// scales it down to fit within maxW x maxH (never up), then renders a
+// thumbnail from the result.
+func processBackgroundImage(data []byte, maxW, maxH int) (processedBackground, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return processedBackground{}, err
+	}
+
+	orientation := jpegOrientation(data)
+	b := src.Bounds()
+	// Orientations 5-8 swap width and height, so fit the rotated size.
+	fitW, fitH := maxW, maxH
+	if orientation >= 5 {
+		fitW, fitH = maxH, maxW
+	}
+	w, h := fitWithin(b.Dx(), b.Dy(), fitW, fitH)
+	img := applyOrientation(scaleImage(src, w, h), orientation)
+
+	var out processedBackground
+	var buf bytes.Buffer
+	if isOpaque(src) {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: backgroundJPEGQuality})
+		out.Ext = ".jpg"
+	} else {
+		err = png.Encode(&buf, img)
+		out.Ext = ".png"
+	}
+	if err != nil {
+		return processedBackground{}, err
+	}
+	out.Image = buf.Bytes()
+
+	ib := img.Bounds()
+	tw, th := fitWithin(ib.Dx(), ib.Dy(), backgroundThumbWidth, ib.Dy())
+	var thumb bytes.Buffer
+	if err := jpeg.Encode(&thumb, scaleImage(img, tw, th), &jpeg.Options{Quality: 80}); err != nil {
+		return processedBackground{}, err
+	}
+	out.Thumb = thumb.Bytes()
+	return out, nil
+}
+
+// fitWithin returns w x h scaled down to fit maxW x maxH, keeping the aspect ratio.
+func fitWithin(w, h, maxW, maxH int) (int, int) {
+	if w <= 0 || h <= 0 || (w <= maxW && h <= maxH) {
+		return w, h
+	}
+	scale := min(float64(maxW)/float64(w), float64(maxH)/float64(h))
+	return max(1, int(float64(w)*scale+0.5)), max(1, int(float64(h)*scale+0.5))
+}
+
+func scaleImage(src image.Image, w, h int) image.Image {
+	b := src.Bounds()
+	if b.Dx() == w && b.Dy() == h {
+		return src
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Src, nil)
+	return dst
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
+
+// applyOrientation rotates/flips img so it displays upright for the given
+// EXIF orientation (1-8).
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirror horizontal
+				dx, dy = w-1-x, y
+			case 3: // rotate 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirror vertical
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90 CW
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotate 90 CCW
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag of a JPEG; 1 (upright) when
+// absent or when data is not a JPEG.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 { // image data starts; no EXIF seen
+			return 1
+		}
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		seg := data[i+4 : i+2+size]
+		if marker == 0xE1 && bytes.HasPrefix(seg, []byte("Exif\x00\x00")) {
+			return exifOrientation(seg[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+// exifOrientation finds tag 0x0112 in the first IFD of a TIFF header.
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+	var bo binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		bo = binary.LittleEndian
+	case "MM":
+		bo = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(bo.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+	n := int(bo.Uint16(tiff[ifd:]))
+	for k := 0; k < n; k++ {
+		e := ifd + 2 + k*12
+		if e+12 > len(tiff) {
+			return 1
+		}
+		if bo.Uint16(tiff[e:]) == 0x0112 {
+			if v := int(bo.Uint16(tiff[e+8:])); v >= 1 && v <= 8 {
+				return v
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// importBackground stores an image in the backgrounds folder. If the same
+// file was imported before, the existing entry is returned with dup = true
+// and nothing is written. Images Go cannot decode are kept as-is without a
+// thumbnail.
+func importBackground(existing []CustomBackground, name, ext string, data []byte, maxW, maxH int) (bg CustomBackground, dup bool, err error) {
+	hash := contentHash(data)
+	for _, e := range existing {
+		if e.Hash == hash {
+			return e, true, nil
+		}
+	}
+
+	if err := os.MkdirAll(backgroundThumbsDir(), 0755); err != nil {
+		return CustomBackground{}, false, err
+	}
+
+	id := uuid.New().String()
+	bg = CustomBackground{Id: id, Name: name, Hash: hash}
+
+	processed, perr := processBackgroundImage(data, maxW, maxH)
+	if perr != nil {
+		processed = processedBackground{Image: data, Ext: ext}
+	}
+	bg.FileName = id + processed.Ext
+	if err := os.WriteFile(filepath.Join(backgroundsDir(), bg.FileName), processed.Image, 0644); err != nil {
+		return CustomBackground{}, false, err
+	}
+	if processed.Thumb != nil {
+		thumbName := id + ".jpg"
+		if err := os.WriteFile(filepath.Join(backgroundThumbsDir(), thumbName), processed.Thumb, 0644); err == nil {
+			bg.ThumbFileName = thumbName
+		}
+	}
+	return bg, false, nil
+}
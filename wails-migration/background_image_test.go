@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func encodeTestJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 128, 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withEXIFOrientation inserts an APP1 EXIF segment carrying the orientation tag.
+func withEXIFOrientation(jpg []byte, orientation uint16) []byte {
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08") // big endian, IFD at 8
+	tiff = binary.BigEndian.AppendUint16(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112) // tag
+	tiff = binary.BigEndian.AppendUint16(tiff, 3)      // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)      // count
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // value padding + next IFD
+
+	seg := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1}
+	app1 = binary.BigEndian.AppendUint16(app1, uint16(len(seg)+2))
+	app1 = append(app1, seg...)
+
+	out := append([]byte{}, jpg[:2]...)
+	out = append(out, app1...)
+	return append(out, jpg[2:]...)
+}
+
+func imageSize(t *testing.T, data []byte) (int, int) {
+	t.Helper()
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("DecodeConfig: %v", err)
+	}
+	return cfg.Width, cfg.Height
+}
+
+// --- fitWithin ---
+
+func TestFitWithin(t *testing.T) {
+	cases := []struct{ w, h, maxW, maxH, wantW, wantH int }{
+		{6000, 4000, 1920, 1080, 1620, 1080},
+		{4000, 6000, 1920, 1080, 720, 1080},
+		{800, 600, 1920, 1080, 800, 600}, // never upscale
+	}
+	for _, c := range cases {
+		if w, h := fitWithin(c.w, c.h, c.maxW, c.maxH); w != c.wantW || h != c.wantH {
+			t.Errorf("fitWithin(%d,%d): got %dx%d, want %dx%d", c.w, c.h, w, h, c.wantW, c.wantH)
+		}
+	}
+}
+
+// --- EXIF orientation ---
+
+func TestJPEGOrientation(t *testing.T) {
+	jpg := encodeTestJPEG(t, 8, 4)
+	if got := jpegOrientation(jpg); got != 1 {
+		t.Errorf("no EXIF: got %d, want 1", got)
+	}
+	if got := jpegOrientation(withEXIFOrientation(jpg, 6)); got != 6 {
+		t.Errorf("got %d, want 6", got)
+	}
+	if got := jpegOrientation([]byte("\x89PNG")); got != 1 {
+		t.Errorf("non-JPEG: got %d, want 1", got)
+	}
+}
+
+func TestApplyOrientation_Rotate90(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	src.Set(0, 0, color.RGBA{255, 0, 0, 255}) // top-left marker
+
+	dst := applyOrientation(src, 6)
+
+	if b := dst.Bounds(); b.Dx() != 2 || b.Dy() != 3 {
+		t.Fatalf("bounds: got %v", b)
+	}
+	// Rotated clockwise, the top-left pixel ends up top-right.
+	if r, _, _, _ := dst.At(1, 0).RGBA(); r>>8 != 255 {
+		t.Errorf("marker not at top-right")
+	}
+}
+
+// --- processBackgroundImage ---
+
+func TestProcessBackgroundImage_DownscalesAndRotates(t *testing.T) {
+	// A 400x200 landscape sensor image shot in portrait (orientation 6).
+	data := withEXIFOrientation(encodeTestJPEG(t, 400, 200), 6)
+
+	out, err := processBackgroundImage(data, 160, 160)
+	if err != nil {
+		t.Fatalf("processBackgroundImage: %v", err)
+	}
+	if out.Ext != ".jpg" {
+		t.Errorf("Ext: got %q", out.Ext)
+	}
+	if w, h := imageSize(t, out.Image); w != 80 || h != 160 {
+		t.Errorf("image: got %dx%d, want 80x160", w, h)
+	}
+	if w, _ := imageSize(t, out.Thumb); w != 80 {
+		t.Errorf("thumb narrower than the limit must keep its width, got %d", w)
+	}
+}
+
+func TestProcessBackgroundImage_ThumbWidth(t *testing.T) {
+	out, err := processBackgroundImage(encodeTestJPEG(t, 600, 300), defaultDisplayWidth, defaultDisplayHeight)
+	if err != nil {
+		t.Fatalf("processBackgroundImage: %v", err)
+	}
+	if w, h := imageSize(t, out.Thumb); w != backgroundThumbWidth || h != 100 {
+		t.Errorf("thumb: got %dx%d", w, h)
+	}
+}
+
+func TestProcessBackgroundImage_TransparentStaysPNG(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 10, 10)) // fully transparent
+	var buf bytes.Buffer
+	png.Encode(&buf, img)
+
+	out, err := processBackgroundImage(buf.Bytes(), defaultDisplayWidth, defaultDisplayHeight)
+	if err != nil || out.Ext != ".png" {
+		t.Errorf("got ext %q, err %v", out.Ext, err)
+	}
+}
+
+// --- importBackground ---
+
+func TestImportBackground_DedupesByContent(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	data := encodeTestJPEG(t, 40, 20)
+
+	first, dup, err := importBackground(nil, "a.jpg", ".jpg", data, 1920, 1080)
+	if err != nil || dup {
+		t.Fatalf("first import: dup=%v err=%v", dup, err)
+	}
+	if first.ThumbFileName == "" || first.Hash == "" {
+		t.Errorf("expected thumbnail and hash, got %+v", first)
+	}
+	if _, err := os.Stat(filepath.Join(backgroundThumbsDir(), first.ThumbFileName)); err != nil {
+		t.Errorf("thumbnail missing: %v", err)
+	}
+
+	second, dup, err := importBackground([]CustomBackground{first}, "copy.jpg", ".jpg", data, 1920, 1080)
+	if err != nil || !dup || second.Id != first.Id {
+		t.Errorf("second import: got %+v dup=%v err=%v", second, dup, err)
+	}
+	entries, _ := os.ReadDir(backgroundsDir())
+	if len(entries) != 2 { // the image and the thumbs folder
+		t.Errorf("expected no second copy, folder has %d entries", len(entries))
+	}
+}
+
+func TestImportBackground_UndecodableKeptAsIs(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	bg, _, err := importBackground(nil, "x.webp", ".webp", []byte("not an image"), 1920, 1080)
+	if err != nil {
+		t.Fatalf("importBackground: %v", err)
+	}
+	if filepath.Ext(bg.FileName) != ".webp" || bg.ThumbFileName != "" {
+		t.Errorf("got %+v", bg)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// BackgroundMaintenanceReport describes what reconcileBackgrounds changed.
+type BackgroundMaintenanceReport struct {
+	Adopted        int      `json:"adopted"`        // orphan images added back to CustomBackgrounds
+	DeletedFiles   int      `json:"deletedFiles"`   // orphan images and thumbnails removed
+	DroppedEntries int      `json:"droppedEntries"` // entries whose image file was missing
+	FreedBytes     int64    `json:"freedBytes"`
+	Errors         []string `json:"errors,omitempty"`
+
+	settingsChanged bool // CustomBackgrounds was modified and needs saving
+}
+
+// reconcileBackgrounds brings settingsDir/backgrounds in line with
+// s.CustomBackgrounds. Files can be left behind when SaveSettings fails
+// after PickBackgroundFile, and entries can lose their file when
+// settings.json is edited by hand.
+//
+// Entries without an image are dropped; stale thumbnail references are
+// cleared. Orphan images are adopted back with their thumbnail, which is
+// regenerated if missing, or deleted when deleteOrphans is set. Thumbnails
+// left without an image are always deleted. Files that are not images are
+// left alone.
+func reconcileBackgrounds(s *Settings, deleteOrphans bool) BackgroundMaintenanceReport {
+	var report BackgroundMaintenanceReport
+	fail := func(err error) {
+		report.Errors = append(report.Errors, err.Error())
+	}
+	remove := func(path string, size int64) {
+		if err := os.Remove(path); err != nil {
+			fail(err)
+			return
+		}
+		report.DeletedFiles++
+		report.FreedBytes += size
+	}
+
+	// Without a reliable listing every entry would look dangling, so stop.
+	images, err := listFiles(backgroundsDir())
+	if err != nil {
+		fail(err)
+		return report
+	}
+	thumbs, err := listFiles(backgroundThumbsDir())
+	if err != nil {
+		fail(err)
+		return report
+	}
+
+	kept := []CustomBackground{}
+	ids := make(map[string]bool)
+	for _, bg := range s.CustomBackgrounds {
+		if _, ok := images[bg.FileName]; !ok {
+			report.DroppedEntries++
+			report.settingsChanged = true
+			clearBackgroundRefs(s, bg.Id)
+			continue
+		}
+		delete(images, bg.FileName)
+		if bg.ThumbFileName != "" {
+			if _, ok := thumbs[bg.ThumbFileName]; ok {
+				delete(thumbs, bg.ThumbFileName)
+			} else {
+				bg.ThumbFileName = ""
+				report.settingsChanged = true
+			}
+		}
+		ids[bg.Id] = true
+		kept = append(kept, bg)
+	}
+
+	for name, size := range images {
+		if _, isImage := imageMimeTypes[strings.ToLower(filepath.Ext(name))]; !isImage {
+			continue
+		}
+		path := filepath.Join(backgroundsDir(), name)
+		if deleteOrphans {
+			remove(path, size)
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		// Files written by PickBackgroundFile are named after their ID.
+		id := strings.TrimSuffix(name, filepath.Ext(name))
+		if _, err := uuid.Parse(id); err != nil || ids[id] {
+			id = uuid.New().String()
+		}
+		ids[id] = true
+		bg := CustomBackground{Id: id, Name: name, FileName: name, Hash: contentHash(data)}
+		if bg.ThumbFileName, err = adoptThumb(name, id, data, thumbs); err != nil {
+			fail(err)
+		}
+		kept = append(kept, bg)
+		report.Adopted++
+		report.settingsChanged = true
+	}
+
+	// Run after adoption, which claims the thumbnails of adopted images.
+	for name, size := range thumbs {
+		remove(filepath.Join(backgroundThumbsDir(), name), size)
+	}
+
+	s.CustomBackgrounds = kept
+	return report
+}
+
+// adoptThumb returns the thumbnail for an adopted image: its existing one,
+// which is removed from thumbs so it is not deleted as an orphan, or a newly
+// rendered one. Images Go cannot decode get none, as in importBackground.
+func adoptThumb(imageName, id string, data []byte, thumbs map[string]int64) (string, error) {
+	// importBackground names the thumbnail after the image's ID.
+	name := strings.TrimSuffix(imageName, filepath.Ext(imageName)) + ".jpg"
+	if _, ok := thumbs[name]; ok {
+		delete(thumbs, name)
+		return name, nil
+	}
+
+	processed, err := processBackgroundImage(data, defaultDisplayWidth, defaultDisplayHeight)
+	if err != nil {
+		return "", nil
+	}
+	if err := os.MkdirAll(backgroundThumbsDir(), 0755); err != nil {
+		return "", err
+	}
+	name = id + ".jpg"
+	if err := os.WriteFile(filepath.Join(backgroundThumbsDir(), name), processed.Thumb, 0644); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// listFiles returns the regular files in dir with their sizes. A missing
+// directory is empty, not an error.
+func listFiles(dir string) (map[string]int64, error) {
+	files := make(map[string]int64)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return files, nil
+		}
+		return nil, err
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		files[e.Name()] = info.Size()
+	}
+	return files, nil
+}
+
+// clearBackgroundRefs resets every setting that points at a removed custom background.
+func clearBackgroundRefs(s *Settings, id string) {
+	ref := "custom:" + id
+	if s.BackgroundID == ref {
+		s.BackgroundID = ""
+	}
+	rot := &s.BackgroundRotation
+	if rot.Rainy == ref {
+		rot.Rainy = ""
+	}
+	images := []string{}
+	for _, img := range rot.Images {
+		if img != ref {
+			images = append(images, img)
+		}
+	}
+	rot.Images = images
+	for i, m := range rot.Months {
+		if m == ref {
+			rot.Months[i] = ""
+		}
+	}
+}
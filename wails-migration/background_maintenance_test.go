@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReconcileBackgrounds_DropsDanglingEntries(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	writeMediaFile(t, backgroundsDir(), "ok.jpg", make([]byte, 10))
+
+	s := defaultSettings
+	s.BackgroundID = "custom:gone"
+	s.BackgroundRotation.Images = []string{"custom:gone", "custom:ok"}
+	s.CustomBackgrounds = []CustomBackground{
+		{Id: "ok", FileName: "ok.jpg", ThumbFileName: "ok.jpg"},
+		{Id: "gone", FileName: "gone.jpg"},
+	}
+
+	report := reconcileBackgrounds(&s, false)
+
+	if report.DroppedEntries != 1 || !report.settingsChanged {
+		t.Errorf("report: %+v", report)
+	}
+	if len(s.CustomBackgrounds) != 1 || s.CustomBackgrounds[0].Id != "ok" {
+		t.Fatalf("entries: %+v", s.CustomBackgrounds)
+	}
+	if s.CustomBackgrounds[0].ThumbFileName != "" {
+		t.Error("missing thumbnail reference should be cleared")
+	}
+	if s.BackgroundID != "" || len(s.BackgroundRotation.Images) != 1 {
+		t.Errorf("references not cleared: %q %v", s.BackgroundID, s.BackgroundRotation.Images)
+	}
+}
+
+func TestReconcileBackgrounds_AdoptsOrphans(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	id := "0b6c1f8e-4a59-4f55-9a38-3a4f2a1f6f11"
+	writeMediaFile(t, backgroundsDir(), id+".png", make([]byte, 10))
+	writeMediaFile(t, backgroundsDir(), "notes.txt", make([]byte, 10))
+	writeMediaFile(t, backgroundThumbsDir(), "stale.jpg", make([]byte, 7))
+
+	s := defaultSettings
+	report := reconcileBackgrounds(&s, false)
+
+	if report.Adopted != 1 || len(s.CustomBackgrounds) != 1 {
+		t.Fatalf("report %+v, entries %+v", report, s.CustomBackgrounds)
+	}
+	if bg := s.CustomBackgrounds[0]; bg.Id != id || bg.FileName != id+".png" || bg.Hash == "" {
+		t.Errorf("adopted entry: %+v", bg)
+	}
+	// Orphan thumbnails are always removed; other files are left alone.
+	if report.DeletedFiles != 1 || report.FreedBytes != 7 {
+		t.Errorf("report: %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(backgroundsDir(), "notes.txt")); err != nil {
+		t.Error("non-image file should be kept")
+	}
+}
+
+func TestReconcileBackgrounds_AdoptedOrphanKeepsThumbnail(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	withThumb := "0b6c1f8e-4a59-4f55-9a38-3a4f2a1f6f11"
+	noThumb := "5d0e2c7a-9f3b-4c1e-8a6d-2b7f4e9c1a30"
+	writeMediaFile(t, backgroundsDir(), withThumb+".jpg", make([]byte, 10))
+	writeMediaFile(t, backgroundThumbsDir(), withThumb+".jpg", make([]byte, 7))
+	writeMediaFile(t, backgroundsDir(), noThumb+".jpg", encodeTestJPEG(t, 400, 300))
+
+	s := defaultSettings
+	report := reconcileBackgrounds(&s, false)
+
+	if report.Adopted != 2 || report.DeletedFiles != 0 || len(report.Errors) != 0 {
+		t.Fatalf("report: %+v", report)
+	}
+	for _, bg := range s.CustomBackgrounds {
+		if bg.ThumbFileName != bg.Id+".jpg" {
+			t.Errorf("%s: thumbnail %q", bg.Id, bg.ThumbFileName)
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(backgroundThumbsDir(), bg.ThumbFileName)); err != nil {
+			t.Errorf("%s: thumbnail file: %v", bg.Id, err)
+		}
+	}
+}
+
+func TestReconcileBackgrounds_DeletesOrphans(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	writeMediaFile(t, backgroundsDir(), "orphan.jpg", make([]byte, 1024))
+	writeMediaFile(t, backgroundsDir(), "kept.jpg", make([]byte, 10))
+
+	s := defaultSettings
+	s.CustomBackgrounds = []CustomBackground{{Id: "kept", FileName: "kept.jpg"}}
+
+	report := reconcileBackgrounds(&s, true)
+
+	if report.DeletedFiles != 1 || report.FreedBytes != 1024 || report.settingsChanged {
+		t.Errorf("report: %+v", report)
+	}
+	if _, err := os.Stat(filepath.Join(backgroundsDir(), "orphan.jpg")); !os.IsNotExist(err) {
+		t.Error("orphan should be deleted")
+	}
+	if len(s.CustomBackgrounds) != 1 {
+		t.Errorf("entries: %+v", s.CustomBackgrounds)
+	}
+}
+
+func TestReconcileBackgrounds_NoFolder(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	s := defaultSettings
+	report := reconcileBackgrounds(&s, true)
+
+	if report.settingsChanged || report.DeletedFiles != 0 || len(report.Errors) != 0 {
+		t.Errorf("report: %+v", report)
+	}
+}
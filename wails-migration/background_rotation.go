@@ -0,0 +1,152 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// backgroundPollInterval bounds each sleep of the rotation loop, so month
+// changes and new weather are picked up without a settings change.
+const backgroundPollInterval = time.Minute
+
+// defaultSlideshowMinutes is used when the slideshow interval is not set.
+const defaultSlideshowMinutes = 10
+
+// BackgroundRotation changes the dashboard background automatically.
+// Background IDs use the same form as Settings.BackgroundID.
+type BackgroundRotation struct {
+	Mode     string   `json:"mode"`     // "" (fixed BackgroundID), "slideshow" or "seasonal"
+	Interval int      `json:"interval"` // slideshow minutes per image
+	Images   []string `json:"images"`   // slideshow order; empty means all custom backgrounds
+	Months   []string `json:"months"`   // seasonal: index 0 = January; "" keeps BackgroundID
+	Rainy    string   `json:"rainy"`    // shown in any mode while it rains; "" to disable
+}
+
+// isRainyWeather reports whether an Open-Meteo WMO weather code means
+// precipitation: drizzle, rain, snow, showers or thunderstorm.
+func isRainyWeather(code int) bool {
+	return (code >= 51 && code <= 67) || (code >= 71 && code <= 77) || (code >= 80 && code <= 99)
+}
+
+// slideshowImages returns the slideshow order, defaulting to every custom background.
+func slideshowImages(s Settings) []string {
+	if len(s.BackgroundRotation.Images) > 0 {
+		return s.BackgroundRotation.Images
+	}
+	ids := make([]string, 0, len(s.CustomBackgrounds))
+	for _, bg := range s.CustomBackgrounds {
+		ids = append(ids, "custom:"+bg.Id)
+	}
+	return ids
+}
+
+func slideshowInterval(s Settings) time.Duration {
+	if s.BackgroundRotation.Interval > 0 {
+		return time.Duration(s.BackgroundRotation.Interval) * time.Minute
+	}
+	return defaultSlideshowMinutes * time.Minute
+}
+
+// pickBackground returns the background to show at now. The slideshow slot
+// is derived from the wall clock, so a restart resumes on the same image.
+func pickBackground(s Settings, now time.Time, weather *WeatherData) string {
+	rot := s.BackgroundRotation
+	if rot.Rainy != "" && weather != nil && isRainyWeather(weather.WeatherCode) {
+		return rot.Rainy
+	}
+	switch rot.Mode {
+	case "slideshow":
+		if images := slideshowImages(s); len(images) > 0 {
+			slot := now.Unix() / int64(slideshowInterval(s)/time.Second)
+			return images[slot%int64(len(images))]
+		}
+	case "seasonal":
+		if m := int(now.Month()) - 1; m < len(rot.Months) && rot.Months[m] != "" {
+			return rot.Months[m]
+		}
+	}
+	return s.BackgroundID
+}
+
+// nextBackgroundCheck returns how long the rotation loop may sleep.
+func nextBackgroundCheck(s Settings, now time.Time) time.Duration {
+	wait := backgroundPollInterval
+	if s.BackgroundRotation.Mode == "slideshow" {
+		secs := int64(slideshowInterval(s) / time.Second)
+		next := time.Unix((now.Unix()/secs+1)*secs, 0)
+		if d := next.Sub(now); d < wait {
+			wait = d
+		}
+	}
+	return wait
+}
+
+// backgroundRotator keeps the latest weather and the background last sent
+// to the UI.
+type backgroundRotator struct {
+	mu      sync.Mutex
+	weather *WeatherData
+	active  string
+	changed chan struct{}
+}
+
+func newBackgroundRotator() *backgroundRotator {
+	return &backgroundRotator{changed: make(chan struct{}, 1)}
+}
+
+// setWeather records the latest weather, e.g. after a dashboard refresh.
+func (r *backgroundRotator) setWeather(w *WeatherData) {
+	r.mu.Lock()
+	r.weather = w
+	r.mu.Unlock()
+	r.notify()
+}
+
+// notify wakes the rotation loop, e.g. after settings changed.
+func (r *backgroundRotator) notify() {
+	select {
+	case r.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (r *backgroundRotator) pick(s Settings, now time.Time) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return pickBackground(s, now, r.weather)
+}
+
+// update records the active background and reports whether it changed.
+func (r *backgroundRotator) update(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if id == r.active {
+		return false
+	}
+	r.active = id
+	return true
+}
+
+// runBackgrounds emits "backgroundChanged" with the background ID whenever
+// the rotation policy selects a different background.
+func (a *App) runBackgrounds() {
+	for {
+		now := time.Now()
+		s := loadSettings()
+		if id := a.background.pick(s, now); a.background.update(id) {
+			runtime.EventsEmit(a.ctx, "backgroundChanged", id)
+		}
+
+		timer := time.NewTimer(nextBackgroundCheck(s, now))
+		select {
+		case <-a.quit:
+			timer.Stop()
+			return
+		case <-a.background.changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
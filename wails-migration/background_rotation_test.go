@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func rotationSettings(rot BackgroundRotation) Settings {
+	s := defaultSettings
+	s.BackgroundID = "fixed.jpg"
+	s.CustomBackgrounds = []CustomBackground{{Id: "a"}, {Id: "b"}, {Id: "c"}}
+	s.BackgroundRotation = rot
+	return s
+}
+
+func TestPickBackground_FixedWithoutRotation(t *testing.T) {
+	if got := pickBackground(rotationSettings(BackgroundRotation{}), at(9, 0, 0), nil); got != "fixed.jpg" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestPickBackground_SlideshowAdvancesEachInterval(t *testing.T) {
+	s := rotationSettings(BackgroundRotation{Mode: "slideshow", Interval: 5})
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+	first := pickBackground(s, start, nil)
+	if same := pickBackground(s, start.Add(4*time.Minute), nil); same != first {
+		t.Errorf("changed within the interval: %q -> %q", first, same)
+	}
+	second := pickBackground(s, start.Add(5*time.Minute), nil)
+	if second == first {
+		t.Errorf("expected a new image after 5 minutes, still %q", first)
+	}
+	if third := pickBackground(s, start.Add(15*time.Minute), nil); third != first {
+		t.Errorf("expected the three images to cycle, got %q", third)
+	}
+}
+
+func TestPickBackground_SlideshowUsesExplicitList(t *testing.T) {
+	s := rotationSettings(BackgroundRotation{Mode: "slideshow", Images: []string{"color:#FFF"}})
+	if got := pickBackground(s, at(9, 0, 0), nil); got != "color:#FFF" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestPickBackground_SeasonalByMonth(t *testing.T) {
+	months := make([]string, 12)
+	months[2] = "spring.jpg" // March
+	s := rotationSettings(BackgroundRotation{Mode: "seasonal", Months: months})
+
+	if got := pickBackground(s, at(9, 0, 0), nil); got != "spring.jpg" {
+		t.Errorf("March: got %q", got)
+	}
+	if got := pickBackground(s, at(9, 0, 0).AddDate(0, 1, 0), nil); got != "fixed.jpg" {
+		t.Errorf("April without an image should keep BackgroundID, got %q", got)
+	}
+}
+
+func TestPickBackground_RainyOverridesRotation(t *testing.T) {
+	s := rotationSettings(BackgroundRotation{Mode: "slideshow", Rainy: "rain.jpg"})
+
+	if got := pickBackground(s, at(9, 0, 0), &WeatherData{WeatherCode: 63}); got != "rain.jpg" {
+		t.Errorf("rain: got %q", got)
+	}
+	if got := pickBackground(s, at(9, 0, 0), &WeatherData{WeatherCode: 2}); got == "rain.jpg" {
+		t.Error("partly cloudy should not show the rainy image")
+	}
+}
+
+func TestIsRainyWeather(t *testing.T) {
+	for _, code := range []int{51, 61, 65, 71, 80, 95} {
+		if !isRainyWeather(code) {
+			t.Errorf("code %d should count as rain", code)
+		}
+	}
+	for _, code := range []int{0, 1, 3, 45, 48} {
+		if isRainyWeather(code) {
+			t.Errorf("code %d should not count as rain", code)
+		}
+	}
+}
+
+func TestNextBackgroundCheck_WakesAtSlideBoundary(t *testing.T) {
+	s := rotationSettings(BackgroundRotation{Mode: "slideshow", Interval: 5})
+	now := time.Date(2026, 3, 2, 9, 4, 30, 0, time.UTC)
+
+	if got := nextBackgroundCheck(s, now); got != 30*time.Second {
+		t.Errorf("got %v, want 30s", got)
+	}
+	if got := nextBackgroundCheck(rotationSettings(BackgroundRotation{}), now); got != backgroundPollInterval {
+		t.Errorf("got %v, want the poll interval", got)
+	}
+}
+
+func TestBackgroundRotator_UpdateReportsChanges(t *testing.T) {
+	r := newBackgroundRotator()
+	if !r.update("a") || r.update("a") || !r.update("b") {
+		t.Error("update should report only actual changes")
+	}
+}
+
+func TestNextBackgroundCheck_OddInterval(t *testing.T) {
+	s := rotationSettings(BackgroundRotation{Mode: "slideshow", Interval: 7})
+	now := time.Unix(7*60*1000+6*60, 0) // one minute before a slot boundary
+
+	if got := nextBackgroundCheck(s, now); got != time.Minute {
+		t.Errorf("got %v, want 1m", got)
+	}
+	if a, b := pickBackground(s, now, nil), pickBackground(s, now.Add(time.Minute), nil); a == b {
+		t.Error("expected the slide to change at the boundary the loop wakes for")
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ===== Bell scheduling =====
+// Bells used to be decided by a 1-second JS timer in the webview, which
+// misses bells when the window is hidden or throttled. The Go side now
+// computes exact bell instants from the timetable and emits "bell" events.
+
+const (
+	// bellGrace is how late a bell may still ring, e.g. after a short sleep.
+	// Bells older than this (long sleep, clock moved forward) are skipped.
+	bellGrace = time.Minute
+	// bellPollInterval bounds each sleep so clock changes are noticed quickly.
+	bellPollInterval = 30 * time.Second
+)
+
+type Bell struct {
+	Period int       `json:"period"` // 0 for extra bells
+	Type   string    `json:"type"`   // "warning", "start", "end" or "extra"
+	Tone   string    `json:"tone"`   // which sound to play: "warning", "start" or "end"
+	Label  string    `json:"label,omitempty"`
+	Sound  string    `json:"sound,omitempty"` // preset name or "custom:<id>", set when the bell rings
+	Time   string    `json:"time"`            // HH:MM
+	At     time.Time `json:"at"`
+}
+
+// BellRule enables one kind of period bell and sets its offset in minutes
+// from the period boundary it belongs to (negative = before).
+type BellRule struct {
+	Enabled bool `json:"enabled"`
+	Offset  int  `json:"offset"`
+}
+
+// PeriodBellOverride turns individual bells of one period on or off,
+// e.g. no end bell for the period before lunch. A bell left unset (nil)
+// keeps ringing as the period rules say.
+type PeriodBellOverride struct {
+	Period  int   `json:"period"`
+	Warning *bool `json:"warning,omitempty"`
+	Start   *bool `json:"start,omitempty"`
+	End     *bool `json:"end,omitempty"`
+}
+
+// rings reports whether the override leaves a bell on.
+func rings(set *bool) bool {
+	return set == nil || *set
+}
+
+// ExtraBell rings at a fixed time regardless of the timetable
+// (morning assembly, lunch, 하교).
+type ExtraBell struct {
+	Id       string `json:"id"`
+	Time     string `json:"time"` // HH:MM
+	Label    string `json:"label"`
+	Tone     string `json:"tone"`               // "warning", "start" or "end"; defaults to "start"
+	Weekdays []int  `json:"weekdays,omitempty"` // 0=Sun .. 6=Sat; empty means Mon-Fri
+}
+
+type BellConfig struct {
+	Warning BellRule             `json:"warning"` // anchored on period start
+	Start   BellRule             `json:"start"`   // anchored on period start
+	End     BellRule             `json:"end"`     // anchored on period end
+	Periods []PeriodBellOverride `json:"periods"`
+	Extra   []ExtraBell          `json:"extra"`
+}
+
+// defaultBellConfig reproduces the original behaviour: a bell 1 minute
+// before start, at start and at end of every period.
+var defaultBellConfig = BellConfig{
+	Warning: BellRule{Enabled: true, Offset: -1},
+	Start:   BellRule{Enabled: true},
+	End:     BellRule{Enabled: true},
+}
+
+// key identifies a bell within its day, used to never ring a bell twice.
+func (b Bell) key() string {
+	return fmt.Sprintf("%s-%d-%s-%s-%s", b.At.Format("20060102"), b.Period, b.Type, b.Time, b.Label)
+}
+
+// parseClock turns "HH:MM" into a time on the given day.
+func parseClock(hhmm string, day time.Time) (time.Time, bool) {
+	parts := strings.SplitN(strings.TrimSpace(hhmm), ":", 2)
+	if len(parts) != 2 {
+		return time.Time{}, false
+	}
+	h, err1 := strconv.Atoi(parts[0])
+	m, err2 := strconv.Atoi(parts[1])
+	if err1 != nil || err2 != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return time.Time{}, false
+	}
+	return time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, day.Location()), true
+}
+
+// bellsForDay lists every bell of the given day in chronological order:
+// the warning/start/end bells of each period according to cfg, plus the
+// extra fixed-time bells scheduled for that weekday.
+func bellsForDay(cfg BellConfig, periods []PeriodTime, day time.Time) []Bell {
+	overrides := make(map[int]PeriodBellOverride)
+	for _, o := range cfg.Periods {
+		overrides[o.Period] = o
+	}
+
+	var bells []Bell
+	add := func(p PeriodTime, typ string, rule BellRule, anchor string, enabled bool) {
+		if !rule.Enabled || !enabled {
+			return
+		}
+		t, ok := parseClock(anchor, day)
+		if !ok {
+			return
+		}
+		t = t.Add(time.Duration(rule.Offset) * time.Minute)
+		bells = append(bells, Bell{Period: p.Period, Type: typ, Tone: typ, Time: t.Format("15:04"), At: t})
+	}
+
+	for _, p := range periods {
+		o := overrides[p.Period]
+		add(p, "warning", cfg.Warning, p.Start, rings(o.Warning))
+		add(p, "start", cfg.Start, p.Start, rings(o.Start))
+		add(p, "end", cfg.End, p.End, rings(o.End))
+	}
+
+	for _, e := range cfg.Extra {
+		if !extraBellOnDay(e, day.Weekday()) {
+			continue
+		}
+		t, ok := parseClock(e.Time, day)
+		if !ok {
+			continue
+		}
+		tone := e.Tone
+		if tone != "warning" && tone != "end" {
+			tone = "start"
+		}
+		bells = append(bells, Bell{Type: "extra", Tone: tone, Label: e.Label, Time: t.Format("15:04"), At: t})
+	}
+
+	sort.SliceStable(bells, func(i, j int) bool {
+		return bells[i].At.Before(bells[j].At)
+	})
+	return bells
+}
+
+func extraBellOnDay(e ExtraBell, wd time.Weekday) bool {
+	if len(e.Weekdays) == 0 {
+		return wd != time.Saturday && wd != time.Sunday
+	}
+	for _, d := range e.Weekdays {
+		if time.Weekday(d) == wd {
+			return true
+		}
+	}
+	return false
+}
+
+// dueBells returns the bells that became due in (last, now] and are at most
+// bellGrace old, skipping any already in fired.
+func dueBells(bells []Bell, last, now time.Time, fired map[string]bool) []Bell {
+	var due []Bell
+	for _, b := range bells {
+		if !b.At.After(last) || b.At.After(now) {
+			continue
+		}
+		if now.Sub(b.At) > bellGrace || fired[b.key()] {
+			continue
+		}
+		due = append(due, b)
+	}
+	return due
+}
+
+// nextBellAfter returns the first bell strictly after now (zero if none).
+func nextBellAfter(bells []Bell, now time.Time) time.Time {
+	for _, b := range bells {
+		if b.At.After(now) {
+			return b.At
+		}
+	}
+	return time.Time{}
+}
+
+// BellMuteStatus tells whether bells are silenced today and why.
+type BellMuteStatus struct {
+	Muted  bool   `json:"muted"`
+	Manual bool   `json:"manual"` // "no bells today" was chosen by the user
+	Reason string `json:"reason"` // "manual", "weekend", "holiday" or ""
+}
+
+// bellMuteStatus mutes bells on weekends, NEIS holidays (휴업일, 공휴일,
+// vacation) and on the day the user chose "no bells today" (muteDate).
+func bellMuteStatus(now time.Time, muteDate string, holidays map[string]bool) BellMuteStatus {
+	today := now.Format("20060102")
+	switch {
+	case muteDate == today:
+		return BellMuteStatus{Muted: true, Manual: true, Reason: "manual"}
+	case now.Weekday() == time.Saturday || now.Weekday() == time.Sunday:
+		return BellMuteStatus{Muted: true, Reason: "weekend"}
+	case holidays[today]:
+		return BellMuteStatus{Muted: true, Reason: "holiday"}
+	}
+	return BellMuteStatus{}
+}
+
+// bellMuteStatus evaluates today's mute state from settings and the cached
+// NEIS holidays. It never waits for NEIS: runHolidays refreshes the cache,
+// and a day it holds nothing for counts as a school day.
+func (a *App) bellMuteStatus(s Settings, now time.Time) BellMuteStatus {
+	today := now.Format("20060102")
+	school := s.OfficeCode + "/" + s.SchoolCode
+	return bellMuteStatus(now, s.MuteBellsDate, a.calendar.cached(school, today, today))
+}
+
+// bellScheduler holds the timetable the bell loop works from and the bells
+// already rung today.
+type bellScheduler struct {
+	mu       sync.Mutex
+	periods  []PeriodTime
+	fired    map[string]bool
+	firedDay string
+	changed  chan struct{}
+}
+
+func newBellScheduler() *bellScheduler {
+	return &bellScheduler{fired: make(map[string]bool), changed: make(chan struct{}, 1)}
+}
+
+// setPeriods replaces the timetable, e.g. after a dashboard refresh.
+func (b *bellScheduler) setPeriods(periods []PeriodTime) {
+	b.mu.Lock()
+	b.periods = append([]PeriodTime(nil), periods...)
+	b.mu.Unlock()
+	b.notify()
+}
+
+// notify wakes the bell loop to recompute, e.g. after the bell rules changed.
+func (b *bellScheduler) notify() {
+	select {
+	case b.changed <- struct{}{}:
+	default:
+	}
+}
+
+// today returns the bells of the given day.
+func (b *bellScheduler) today(cfg BellConfig, now time.Time) []Bell {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bellsForDay(cfg, b.periods, now)
+}
+
+// take returns the bells due in (last, now] and records them as rung.
+func (b *bellScheduler) take(cfg BellConfig, last, now time.Time) []Bell {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if day := now.Format("20060102"); day != b.firedDay {
+		b.fired = make(map[string]bool)
+		b.firedDay = day
+	}
+	due := dueBells(bellsForDay(cfg, b.periods, now), last, now, b.fired)
+	for _, bell := range due {
+		b.fired[bell.key()] = true
+	}
+	return due
+}
+
+// runBells emits a "bell" event at each bell instant. It wakes at least every
+// bellPollInterval, so a wake from sleep or a clock change is noticed; the
+// fired set makes sure a clock set backwards never rings a bell twice.
+func (a *App) runBells() {
+	last := time.Now()
+	for {
+		now := time.Now()
+		if now.Before(last) {
+			// Clock moved backwards: resume from the new time.
+			last = now
+		}
+
+		s := loadSettings()
+		if due := a.bells.take(s.Bells, last, now); len(due) > 0 && s.AlarmEnabled {
+			// Muted bells are still marked as rung so they don't fire late
+			// if the mute is lifted.
+			if status := a.bellMuteStatus(s, now); !status.Muted {
+				for _, bell := range due {
+					bell.Sound = soundForBell(s, bell)
+					runtime.EventsEmit(a.ctx, "bell", bell)
+				}
+			}
+		}
+		// The manual mute expires at midnight; keep the tray checkbox in sync.
+		a.syncTrayMute(s.MuteBellsDate == now.Format("20060102"))
+		last = now
+
+		wait := bellPollInterval
+		if next := nextBellAfter(a.bells.today(s.Bells, now), now); !next.IsZero() {
+			if d := next.Sub(now); d < wait {
+				wait = d
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-a.quit:
+			timer.Stop()
+			return
+		case <-a.bells.changed:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
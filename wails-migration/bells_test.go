@@ -0,0 +1,288 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+// at builds a local time on 2026-03-02 (a Monday).
+func at(h, m, s int) time.Time {
+	return time.Date(2026, 3, 2, h, m, s, 0, time.Local)
+}
+
+var testPeriods = []PeriodTime{
+	{Period: 1, Start: "09:00", End: "09:40"},
+	{Period: 2, Start: "09:50", End: "10:30"},
+}
+
+// --- bellsForDay ---
+
+func TestBellsForDay_WarningStartEndInOrder(t *testing.T) {
+	bells := bellsForDay(defaultBellConfig, testPeriods, at(0, 0, 0))
+
+	want := []struct {
+		period int
+		typ    string
+		time   string
+	}{
+		{1, "warning", "08:59"},
+		{1, "start", "09:00"},
+		{1, "end", "09:40"},
+		{2, "warning", "09:49"},
+		{2, "start", "09:50"},
+		{2, "end", "10:30"},
+	}
+	if len(bells) != len(want) {
+		t.Fatalf("expected %d bells, got %d", len(want), len(bells))
+	}
+	for i, w := range want {
+		b := bells[i]
+		if b.Period != w.period || b.Type != w.typ || b.Time != w.time {
+			t.Errorf("bell %d: got %d/%s/%s, want %d/%s/%s", i, b.Period, b.Type, b.Time, w.period, w.typ, w.time)
+		}
+	}
+	if !bells[1].At.Equal(at(9, 0, 0)) {
+		t.Errorf("start bell At: got %v", bells[1].At)
+	}
+}
+
+func TestBellsForDay_InvalidTimesSkipped(t *testing.T) {
+	bells := bellsForDay(defaultBellConfig, []PeriodTime{{Period: 1, Start: "9시", End: "25:00"}}, at(0, 0, 0))
+	if len(bells) != 0 {
+		t.Errorf("expected no bells, got %+v", bells)
+	}
+}
+
+// --- dueBells ---
+
+func TestDueBells_FiresInsideWindow(t *testing.T) {
+	bells := bellsForDay(defaultBellConfig, testPeriods, at(0, 0, 0))
+
+	due := dueBells(bells, at(8, 59, 50), at(9, 0, 0), map[string]bool{})
+
+	if len(due) != 1 || due[0].Type != "start" || due[0].Period != 1 {
+		t.Errorf("got %+v", due)
+	}
+}
+
+func TestDueBells_LateWakeWithinGraceStillRings(t *testing.T) {
+	bells := bellsForDay(defaultBellConfig, testPeriods, at(0, 0, 0))
+
+	// A throttled tick lands 40 seconds after the start bell.
+	due := dueBells(bells, at(8, 59, 30), at(9, 0, 40), map[string]bool{})
+
+	if len(due) != 1 || due[0].Type != "start" {
+		t.Fatalf("expected the start bell, got %+v", due)
+	}
+}
+
+func TestDueBells_LongSleepSkipsStaleBells(t *testing.T) {
+	bells := bellsForDay(defaultBellConfig, testPeriods, at(0, 0, 0))
+
+	// Machine slept from 08:00 to 09:45: only bells within the grace window may ring.
+	due := dueBells(bells, at(8, 0, 0), at(9, 45, 0), map[string]bool{})
+
+	if len(due) != 0 {
+		t.Errorf("expected stale bells to be skipped, got %+v", due)
+	}
+}
+
+func TestDueBells_AlreadyFiredNotRepeated(t *testing.T) {
+	bells := bellsForDay(defaultBellConfig, testPeriods, at(0, 0, 0))
+	fired := map[string]bool{bells[1].key(): true}
+
+	due := dueBells(bells, at(8, 59, 59), at(9, 0, 1), fired)
+
+	if len(due) != 0 {
+		t.Errorf("expected no repeat, got %+v", due)
+	}
+}
+
+// --- bellScheduler ---
+
+func TestBellScheduler_ClockSetBackDoesNotRingTwice(t *testing.T) {
+	b := newBellScheduler()
+	b.setPeriods(testPeriods)
+
+	if got := b.take(defaultBellConfig, at(8, 59, 59), at(9, 0, 0)); len(got) != 1 {
+		t.Fatalf("expected start bell, got %+v", got)
+	}
+	// Clock jumps back a few seconds and passes 09:00 again.
+	if got := b.take(defaultBellConfig, at(8, 59, 57), at(9, 0, 2)); len(got) != 0 {
+		t.Errorf("bell rang twice after clock change: %+v", got)
+	}
+}
+
+func TestBellScheduler_FiredResetsOnNewDay(t *testing.T) {
+	b := newBellScheduler()
+	b.setPeriods(testPeriods)
+
+	b.take(defaultBellConfig, at(8, 59, 59), at(9, 0, 0))
+	next := func(h, m, s int) time.Time { return at(h, m, s).AddDate(0, 0, 1) }
+	if got := b.take(defaultBellConfig, next(8, 59, 59), next(9, 0, 0)); len(got) != 1 {
+		t.Errorf("expected the next day's bell to ring, got %+v", got)
+	}
+}
+
+func TestNextBellAfter(t *testing.T) {
+	bells := bellsForDay(defaultBellConfig, testPeriods, at(0, 0, 0))
+	if got := nextBellAfter(bells, at(9, 0, 0)); !got.Equal(at(9, 40, 0)) {
+		t.Errorf("got %v, want 09:40", got)
+	}
+	if got := nextBellAfter(bells, at(11, 0, 0)); !got.IsZero() {
+		t.Errorf("expected zero time after the last bell, got %v", got)
+	}
+}
+
+// --- bell rules ---
+
+func TestBellsForDay_CustomWarningOffset(t *testing.T) {
+	cfg := defaultBellConfig
+	cfg.Warning.Offset = -2
+
+	bells := bellsForDay(cfg, testPeriods[:1], at(0, 0, 0))
+
+	if bells[0].Type != "warning" || bells[0].Time != "08:58" {
+		t.Errorf("expected a 2-minute pre-bell at 08:58, got %+v", bells[0])
+	}
+}
+
+func TestBellsForDay_DisabledRule(t *testing.T) {
+	cfg := defaultBellConfig
+	cfg.End.Enabled = false
+
+	for _, b := range bellsForDay(cfg, testPeriods, at(0, 0, 0)) {
+		if b.Type == "end" {
+			t.Errorf("end bells disabled but got %+v", b)
+		}
+	}
+}
+
+func TestBellsForDay_PeriodOverride(t *testing.T) {
+	cfg := defaultBellConfig
+	// No end bell for period 2 (the period before lunch).
+	off := false
+	cfg.Periods = []PeriodBellOverride{{Period: 2, End: &off}}
+
+	bells := bellsForDay(cfg, testPeriods, at(0, 0, 0))
+
+	if len(bells) != 5 {
+		t.Fatalf("expected 5 bells, got %d", len(bells))
+	}
+	for _, b := range bells {
+		if b.Period == 2 && b.Type == "end" {
+			t.Errorf("period 2 end bell should be disabled")
+		}
+	}
+}
+
+func TestBellsForDay_PartialOverrideFromJSONKeepsOtherBells(t *testing.T) {
+	var cfg BellConfig
+	data := `{"warning":{"enabled":true,"offset":-1},"start":{"enabled":true},"end":{"enabled":true},
+		"periods":[{"period":1,"end":false}]}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	var types []string
+	for _, b := range bellsForDay(cfg, testPeriods, at(0, 0, 0)) {
+		if b.Period == 1 {
+			types = append(types, b.Type)
+		}
+	}
+	if strings.Join(types, ",") != "warning,start" {
+		t.Errorf("period 1 bells: got %v, want warning and start only", types)
+	}
+}
+
+func TestBellsForDay_ExtraBells(t *testing.T) {
+	cfg := defaultBellConfig
+	cfg.Extra = []ExtraBell{
+		{Time: "08:40", Label: "아침 조회"},
+		{Time: "12:10", Label: "점심", Tone: "end"},
+		{Time: "15:00", Label: "주말 행사", Weekdays: []int{int(time.Saturday)}},
+	}
+
+	bells := bellsForDay(cfg, testPeriods, at(0, 0, 0)) // Monday
+
+	if bells[0].Type != "extra" || bells[0].Label != "아침 조회" || bells[0].Tone != "start" {
+		t.Errorf("expected assembly bell first with default tone, got %+v", bells[0])
+	}
+	last := bells[len(bells)-1]
+	if last.Label != "점심" || last.Tone != "end" || last.Time != "12:10" {
+		t.Errorf("expected lunch bell last, got %+v", last)
+	}
+	for _, b := range bells {
+		if b.Label == "주말 행사" {
+			t.Error("Saturday-only bell must not ring on Monday")
+		}
+	}
+}
+
+func TestBellsForDay_ExtraBellsDefaultToWeekdays(t *testing.T) {
+	cfg := BellConfig{Extra: []ExtraBell{{Time: "08:40", Label: "아침 조회"}}}
+	sunday := at(0, 0, 0).AddDate(0, 0, -1)
+
+	if got := bellsForDay(cfg, nil, sunday); len(got) != 0 {
+		t.Errorf("expected no weekday-default extra bells on Sunday, got %+v", got)
+	}
+}
+
+// --- bellMuteStatus ---
+
+func TestBellMuteStatus(t *testing.T) {
+	monday := at(9, 0, 0)
+	tests := []struct {
+		name     string
+		now      time.Time
+		muteDate string
+		holidays map[string]bool
+		want     BellMuteStatus
+	}{
+		{"school day", monday, "", nil, BellMuteStatus{}},
+		{"weekend", monday.AddDate(0, 0, -1), "", nil, BellMuteStatus{Muted: true, Reason: "weekend"}},
+		{"holiday", monday, "", map[string]bool{"20260302": true}, BellMuteStatus{Muted: true, Reason: "holiday"}},
+		{"manual today", monday, "20260302", nil, BellMuteStatus{Muted: true, Manual: true, Reason: "manual"}},
+		{"manual expired at midnight", monday, "20260301", nil, BellMuteStatus{}},
+	}
+	for _, tt := range tests {
+		if got := bellMuteStatus(tt.now, tt.muteDate, tt.holidays); got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAppBellMuteStatus_ReadsOnlyTheHolidayCache(t *testing.T) {
+	monday := at(9, 0, 0)
+	s := Settings{OfficeCode: "B10", SchoolCode: "7010000"}
+	a := &App{}
+
+	// Nothing cached yet: treated as a school day rather than waiting for NEIS.
+	if got := a.bellMuteStatus(s, monday); got.Muted {
+		t.Errorf("unknown holidays should not mute, got %+v", got)
+	}
+
+	a.calendar.school = "B10/7010000"
+	a.calendar.from, a.calendar.to = "20260301", "20260331"
+	a.calendar.holidays = map[string]bool{"20260302": true}
+	if got := a.bellMuteStatus(s, monday); got.Reason != "holiday" {
+		t.Errorf("expected a holiday mute from the cache, got %+v", got)
+	}
+}
+
+func TestSchoolCalendarLookup_RemembersFailures(t *testing.T) {
+	now := at(9, 0, 0)
+	c := &schoolCalendar{failedSchool: "B10/7010000", failed: now.Add(-time.Minute)}
+
+	if h, ok := c.lookup("B10/7010000", "20260302", "20260302", now); !ok || len(h) != 0 {
+		t.Errorf("recent failure: got %v, %v; want an empty set without a request", h, ok)
+	}
+	if _, ok := c.lookup("B10/7010000", "20260302", "20260302", now.Add(holidayRetryAfterError)); ok {
+		t.Error("expected a new request once the failure expired")
+	}
+	if _, ok := c.lookup("B10/7020000", "20260302", "20260302", now); ok {
+		t.Error("a failure for another school should not suppress the request")
+	}
+}
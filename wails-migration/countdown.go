@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Countdown is a D-day target pinned by the teacher, stored in Settings.
+type Countdown struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Date   string `json:"date"`             // YYYYMMDD
+	Source string `json:"source,omitempty"` // source of the pinned event; empty for custom dates
+}
+
+type CountdownStatus struct {
+	Id     string `json:"id"`
+	Name   string `json:"name"`
+	Date   string `json:"date"`
+	Source string `json:"source,omitempty"`
+	Label  string `json:"label"` // "D-23", "D-Day"
+	// DaysLeft counts calendar days from today to the target date.
+	DaysLeft int `json:"daysLeft"`
+	// SchoolDaysLeft counts school days after today and before the target date,
+	// skipping weekends and NEIS holidays.
+	SchoolDaysLeft int `json:"schoolDaysLeft"`
+}
+
+func newCountdown(name, date, source string) (Countdown, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Countdown{}, errors.New("이름을 입력해 주세요")
+	}
+	d := parseDateToYYYYMMDD(date)
+	if d == "" {
+		return Countdown{}, errors.New("날짜 형식이 올바르지 않습니다")
+	}
+	if d < todayStr() {
+		return Countdown{}, errors.New("이미 지난 날짜입니다")
+	}
+	return Countdown{Id: uuid.New().String(), Name: name, Date: d, Source: source}, nil
+}
+
+// pruneCountdowns drops targets whose date is before today.
+func pruneCountdowns(list []Countdown, today string) ([]Countdown, bool) {
+	kept := []Countdown{}
+	for _, c := range list {
+		if c.Date >= today {
+			kept = append(kept, c)
+		}
+	}
+	return kept, len(kept) != len(list)
+}
+
+// countSchoolDays counts school days in [from, to).
+func countSchoolDays(from, to time.Time, holidays map[string]bool) int {
+	n := 0
+	for d := from; d.Before(to); d = d.AddDate(0, 0, 1) {
+		if isSchoolDay(d, holidays) {
+			n++
+		}
+	}
+	return n
+}
+
+// daysBetween returns the number of calendar days from a to b, ignoring the
+// time of day and any DST shifts of the local zone.
+func daysBetween(a, b time.Time) int {
+	da := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	db := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(db.Sub(da).Hours() / 24)
+}
+
+func countdownLabel(daysLeft int) string {
+	if daysLeft == 0 {
+		return "D-Day"
+	}
+	return fmt.Sprintf("D-%d", daysLeft)
+}
+
+// countdownStatuses computes the remaining days for every target, nearest first.
+func countdownStatuses(list []Countdown, today time.Time, holidays map[string]bool) []CountdownStatus {
+	statuses := []CountdownStatus{}
+	for _, c := range list {
+		target, err := time.ParseInLocation("20060102", c.Date, time.Local)
+		if err != nil || target.Before(today) {
+			continue
+		}
+		days := daysBetween(today, target)
+		statuses = append(statuses, CountdownStatus{
+			Id:             c.Id,
+			Name:           c.Name,
+			Date:           c.Date,
+			Source:         c.Source,
+			Label:          countdownLabel(days),
+			DaysLeft:       days,
+			SchoolDaysLeft: countSchoolDays(today.AddDate(0, 0, 1), target, holidays),
+		})
+	}
+
+	sort.SliceStable(statuses, func(i, j int) bool {
+		return statuses[i].Date < statuses[j].Date
+	})
+	return statuses
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// day builds a local midnight time for the given date.
+func day(y int, m time.Month, d int) time.Time {
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
+
+// --- countSchoolDays ---
+
+func TestCountSchoolDays_SkipsWeekends(t *testing.T) {
+	// 2026-03-02 (Mon) .. 2026-03-09 (Mon, exclusive): five weekdays.
+	if got := countSchoolDays(day(2026, 3, 2), day(2026, 3, 9), nil); got != 5 {
+		t.Errorf("got %d, want 5", got)
+	}
+}
+
+func TestCountSchoolDays_SkipsHolidays(t *testing.T) {
+	holidays := map[string]bool{"20260303": true, "20260307": true} // Tue + a Saturday
+	if got := countSchoolDays(day(2026, 3, 2), day(2026, 3, 9), holidays); got != 4 {
+		t.Errorf("got %d, want 4", got)
+	}
+}
+
+func TestCountSchoolDays_EmptyRange(t *testing.T) {
+	if got := countSchoolDays(day(2026, 3, 2), day(2026, 3, 2), nil); got != 0 {
+		t.Errorf("got %d, want 0", got)
+	}
+}
+
+// --- countdownStatuses ---
+
+func TestCountdownStatuses_DaysAndLabels(t *testing.T) {
+	today := day(2026, 3, 2) // Monday
+	list := []Countdown{
+		{Id: "b", Name: "운동회", Date: "20260306"},
+		{Id: "a", Name: "오늘", Date: "20260302"},
+		{Id: "c", Name: "지난 행사", Date: "20260301"},
+	}
+
+	got := countdownStatuses(list, today, map[string]bool{"20260304": true})
+
+	if len(got) != 2 {
+		t.Fatalf("expected past target to be skipped, got %d entries", len(got))
+	}
+	if got[0].Id != "a" || got[0].Label != "D-Day" || got[0].DaysLeft != 0 {
+		t.Errorf("first: got %+v", got[0])
+	}
+	// Tue, (Wed holiday), Thu -> 2 school days before Friday's event.
+	if got[1].Label != "D-4" || got[1].DaysLeft != 4 || got[1].SchoolDaysLeft != 2 {
+		t.Errorf("second: got %+v", got[1])
+	}
+}
+
+func TestDaysBetween_AcrossMonthAndYear(t *testing.T) {
+	if got := daysBetween(day(2026, 12, 30), day(2027, 1, 2)); got != 3 {
+		t.Errorf("got %d, want 3", got)
+	}
+}
+
+// --- pruneCountdowns ---
+
+func TestPruneCountdowns(t *testing.T) {
+	list := []Countdown{{Id: "old", Date: "20260301"}, {Id: "today", Date: "20260302"}}
+
+	kept, pruned := pruneCountdowns(list, "20260302")
+
+	if !pruned || len(kept) != 1 || kept[0].Id != "today" {
+		t.Errorf("got kept=%+v pruned=%v", kept, pruned)
+	}
+	if _, pruned := pruneCountdowns(kept, "20260302"); pruned {
+		t.Error("expected no pruning on a current list")
+	}
+}
+
+// --- newCountdown ---
+
+func TestNewCountdown_Validation(t *testing.T) {
+	if _, err := newCountdown("", dateAfterDays(3), ""); err == nil {
+		t.Error("expected error for empty name")
+	}
+	if _, err := newCountdown("방학", "언젠가", ""); err == nil {
+		t.Error("expected error for invalid date")
+	}
+	if _, err := newCountdown("방학", dateAfterDays(-1), ""); err == nil {
+		t.Error("expected error for a past date")
+	}
+	c, err := newCountdown(" 방학 ", dateAfterDays(3), "neis")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Id == "" || c.Name != "방학" || c.Source != "neis" {
+		t.Errorf("got %+v", c)
+	}
+}
+
+// --- isSchoolDay / holidayDates ---
+
+func TestHolidayDates_OnlyHolidayEvents(t *testing.T) {
+	events := []ScheduleEvent{
+		{Date: "20260301", Name: "삼일절", Holiday: true},
+		{Date: "20260302", Name: "입학식"},
+	}
+	got := holidayDates(events)
+	if !got["20260301"] || got["20260302"] {
+		t.Errorf("got %v", got)
+	}
+	if !isSchoolDay(day(2026, 3, 2), got) {
+		t.Error("2026-03-02 (Mon) should be a school day")
+	}
+	if isSchoolDay(day(2026, 3, 7), got) {
+		t.Error("Saturday should not be a school day")
+	}
+}
@@ -225,97 +225,33 @@ export function playPresetAlarm(preset: string, type: AlarmType): void {
   p[type]();
 }
 
-export function playCustomAlarm(dataUrl: string): void {
-  if (!dataUrl) return;
-  const audio = new Audio(dataUrl);
+export function playCustomAlarm(url: string): void {
+  if (!url) return;
+  const audio = new Audio(url);
   audio.volume = 0.5;
   audio.play().catch(() => {});
 }
 
-const playedAlarms = new Set<string>();
-
 export interface AlarmEvent {
   period: number;
-  type: AlarmType;
-}
-
-export function getAlarmKey(period: number, type: "warning" | "start" | "end"): string {
-  const now = new Date();
-  const dateStr = `${now.getFullYear()}-${now.getMonth()}-${now.getDate()}`;
-  return `${dateStr}-${period}-${type}`;
+  type: AlarmType | "extra";
+  tone: AlarmType;
+  label?: string;
+  sound?: string;
+  time: string;
 }
 
-export function shouldPlayAlarm(key: string): boolean {
-  if (playedAlarms.has(key)) return false;
-  playedAlarms.add(key);
-  return true;
-}
-
-export function checkAndPlayAlarms(
-  periods: Array<{ period: number; start: string; end: string }>,
-  enabled: boolean,
-  alarmSound: string = "classic",
-  customAlarmData: string = "",
-): AlarmEvent | null {
-  if (!enabled) return null;
-
-  const now = new Date();
-  const h = now.getHours();
-  const m = now.getMinutes();
-  const s = now.getSeconds();
-  const currentMinutes = h * 60 + m;
-
-  if (s > 2) return null;
-
-  for (const p of periods) {
-    const [startH, startM] = p.start.split(":").map(Number);
-    const [endH, endM] = p.end.split(":").map(Number);
-    const startMin = startH * 60 + startM;
-    const endMin = endH * 60 + endM;
-
-    if (currentMinutes === startMin - 1) {
-      const key = getAlarmKey(p.period, "warning");
-      if (shouldPlayAlarm(key)) {
-        if (alarmSound === "custom" && customAlarmData) {
-          playCustomAlarm(customAlarmData);
-        } else {
-          playPresetAlarm(alarmSound, "warning");
-        }
-        return { period: p.period, type: "warning" };
-      }
-    }
-
-    if (currentMinutes === startMin) {
-      const key = getAlarmKey(p.period, "start");
-      if (shouldPlayAlarm(key)) {
-        if (alarmSound === "custom" && customAlarmData) {
-          playCustomAlarm(customAlarmData);
-        } else {
-          playPresetAlarm(alarmSound, "start");
-        }
-        return { period: p.period, type: "start" };
+// playAlarm plays a preset by name, or a custom sound given as "custom:<id>".
+export function playAlarm(type: AlarmType, sound: string = "classic"): void {
+  if (sound.startsWith("custom:")) {
+    window.go.main.App.GetCustomSoundURL(sound.slice(7)).then((url: string) => {
+      if (url) {
+        playCustomAlarm(url);
+      } else {
+        playPresetAlarm("classic", type);
       }
-    }
-
-    if (currentMinutes === endMin) {
-      const key = getAlarmKey(p.period, "end");
-      if (shouldPlayAlarm(key)) {
-        if (alarmSound === "custom" && customAlarmData) {
-          playCustomAlarm(customAlarmData);
-        } else {
-          playPresetAlarm(alarmSound, "end");
-        }
-        return { period: p.period, type: "end" };
-      }
-    }
-  }
-
-  return null;
-}
-
-export function resetAlarmsIfNewDay(): void {
-  const now = new Date();
-  if (now.getHours() === 0 && now.getMinutes() === 0) {
-    playedAlarms.clear();
+    });
+  } else {
+    playPresetAlarm(sound, type);
   }
 }
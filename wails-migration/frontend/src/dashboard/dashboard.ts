@@ -1,7 +1,7 @@
 // ===== Dashboard Logic =====
 // Uses Wails bindings instead of Electrobun RPC
 
-import type { Settings, DashboardData, MealData, ScheduleEvent } from "../types";
+import type { Settings, DashboardData, DashboardDone, PersonalEvent, MealData, ScheduleEvent, SettingsStatus, ImportOptions, PINStatus, APIUsage, APIKeyValidation } from "../types";
 import {
   getPeriods,
   getSubjects,
@@ -11,8 +11,7 @@ import {
   getStatusBadgeClass,
 } from "./schedule";
 import {
-  checkAndPlayAlarms,
-  resetAlarmsIfNewDay,
+  playAlarm,
   type AlarmEvent,
 } from "./audio";
 import {
@@ -69,6 +68,15 @@ function getPMLevelLabel(level: PMLevel): string {
 
 // ===== State =====
 let dashboardData: DashboardData | null = null;
+const EMPTY_DASHBOARD: DashboardData = {
+  weather: null,
+  airQuality: null,
+  meals: [],
+  events: [],
+  timetable: null,
+  studyPlan: null,
+  sources: {},
+};
 let cachedSettings: Settings | null = null;
 let lastFetchTime = 0;
 const FETCH_INTERVAL = 30 * 60 * 1000;
@@ -87,8 +95,8 @@ function getSettings(): Settings {
     customApiKey: "",
     alarmEnabled: true,
     alarmSound: "classic",
-    customAlarmData: "",
-    customAlarmName: "",
+    bellSounds: { warning: "", start: "", end: "", extra: "" },
+    customSounds: [],
     backgroundId: "",
     customBackgrounds: [],
   };
@@ -101,20 +109,36 @@ declare global {
       main: {
         App: {
           GetSettings(): Promise<Settings>;
-          SaveSettings(s: Settings): Promise<void>;
+          GetDefaultSettings(): Promise<Settings>;
+          SaveSettings(s: Settings): Promise<string>;
+          GetSettingsStatus(): Promise<SettingsStatus>;
+          ExportSettings(includeSecrets: boolean): Promise<string>;
+          ImportSettings(opts: ImportOptions): Promise<string>;
+          GetPINStatus(): Promise<PINStatus>;
+          UnlockAdmin(pin: string): Promise<string>;
+          LockAdmin(): Promise<void>;
+          SetAdminPIN(pin: string): Promise<string>;
           FetchDashboardData(): Promise<DashboardData>;
+          StreamDashboardData(): Promise<number>;
+          CancelDashboardFetch(): Promise<void>;
+          ValidateAPIKey(key: string): Promise<APIKeyValidation>;
+          GetAPIUsage(): Promise<APIUsage>;
           SearchSchool(name: string): Promise<{ schools: any[]; error: string }>;
           GeocodeAddress(addr: string): Promise<any>;
           PickAlarmFile(): Promise<any>;
+          GetCustomSoundURL(id: string): Promise<string>;
+          RemoveCustomSound(id: string): Promise<string>;
           PickBackgroundFile(): Promise<any>;
+          GetActiveBackground(): Promise<string>;
           GetCustomBackgroundURL(id: string): Promise<string>;
-          RemoveCustomBackground(id: string): Promise<void>;
+          GetCustomBackgroundThumbURL(id: string): Promise<string>;
+          RemoveCustomBackground(id: string): Promise<string>;
           GetAutoStart(): Promise<boolean>;
-          SetAutoStart(enabled: boolean): Promise<void>;
+          SetAutoStart(enabled: boolean): Promise<string>;
           MinimizeWindow(): Promise<void>;
           MaximizeWindow(): Promise<void>;
           CloseWindow(): Promise<void>;
-          GetNeisAPIKey(): Promise<string>;
+          QuitApp(): Promise<void>;
           GetAppVersion(): Promise<string>;
           CheckForUpdate(): Promise<any>;
           DownloadAndRunUpdate(url: string): Promise<string>;
@@ -137,8 +161,9 @@ export async function initDashboard(): Promise<void> {
   setupWindowControls();
   updateHeader();
   updateAppVersion();
-  applyBackground(cachedSettings);
+  applyBackground(await window.go.main.App.GetActiveBackground());
   updateClock();
+  listenForDashboardStream();
   await loadDashboardData();
   startUpdateLoop();
 
@@ -149,9 +174,30 @@ export async function initDashboard(): Promise<void> {
   window.runtime.EventsOn("settingsChanged", async () => {
     cachedSettings = await window.go.main.App.GetSettings();
     updateHeader();
-    applyBackground(cachedSettings);
+    applyBackground(await window.go.main.App.GetActiveBackground());
+    // A fetch still running uses the old settings
+    await window.go.main.App.CancelDashboardFetch();
     loadDashboardData();
   });
+
+  // Slideshow, seasonal and rainy-day backgrounds are picked by the Go backend
+  window.runtime.EventsOn("backgroundChanged", (bgId: string) => {
+    applyBackground(bgId);
+  });
+
+  // Bells are scheduled by the Go backend so they fire even when the webview is throttled
+  window.runtime.EventsOn("bell", (bell: AlarmEvent) => {
+    playAlarm(bell.tone, bell.sound);
+    showAlarmPopup(bell);
+  });
+
+  // Reminders of personal events, also scheduled by the Go backend
+  window.runtime.EventsOn("reminder", (event: PersonalEvent) => {
+    if (cachedSettings?.alarmEnabled !== false) {
+      playAlarm("warning", cachedSettings?.alarmSound);
+    }
+    showPopup("\uD83D\uDCC5", event.detail ? `${event.name} - ${event.detail}` : event.name, "alarm-warning");
+  });
 }
 
 // ===== Auto Update Check =====
@@ -267,29 +313,29 @@ async function updateAppVersion(): Promise<void> {
 
 // ===== Background =====
 
-async function applyBackground(settings: Settings): Promise<void> {
+async function applyBackground(bgId: string): Promise<void> {
   const frame = document.querySelector(".window-frame") as HTMLElement;
   if (!frame) return;
 
-  if (!settings.backgroundId) {
+  if (!bgId) {
     frame.style.removeProperty("--bg-image");
     frame.style.removeProperty("--bg-color");
     return;
   }
 
-  if (settings.backgroundId.startsWith("color:")) {
+  if (bgId.startsWith("color:")) {
     frame.style.removeProperty("--bg-image");
-    frame.style.setProperty("--bg-color", settings.backgroundId.slice(6));
+    frame.style.setProperty("--bg-color", bgId.slice(6));
     return;
   }
 
   frame.style.removeProperty("--bg-color");
 
-  if (settings.backgroundId.startsWith("custom:")) {
-    const customId = settings.backgroundId.slice(7);
-    const dataURL = await window.go.main.App.GetCustomBackgroundURL(customId);
-    if (dataURL) {
-      frame.style.setProperty("--bg-image", `url('${dataURL}')`);
+  if (bgId.startsWith("custom:")) {
+    const customId = bgId.slice(7);
+    const bgURL = await window.go.main.App.GetCustomBackgroundURL(customId);
+    if (bgURL) {
+      frame.style.setProperty("--bg-image", `url('${bgURL}')`);
     } else {
       frame.style.removeProperty("--bg-image");
     }
@@ -297,7 +343,7 @@ async function applyBackground(settings: Settings): Promise<void> {
   }
 
   // Use relative path from public/assets/bg/
-  const url = `/assets/bg/${settings.backgroundId}`;
+  const url = `/assets/bg/${bgId}`;
   frame.style.setProperty("--bg-image", `url('${url}')`);
 }
 
@@ -387,7 +433,11 @@ function updateMeals(): void {
   const meals = dashboardData?.meals ?? [];
 
   if (meals.length === 0) {
-    container.innerHTML = '<div class="loading-placeholder">급식 정보가 없습니다</div>';
+    const source = dashboardData?.sources?.meals;
+    const message = source?.status === "error" ? "급식 정보를 불러오지 못했습니다" : "급식 정보가 없습니다";
+    container.innerHTML = `<div class="loading-placeholder">${message}</div>`;
+    const placeholder = container.firstElementChild as HTMLElement;
+    if (source?.error) placeholder.title = source.error;
     return;
   }
 
@@ -569,16 +619,43 @@ function updateStudyPlanNavButtons(): void {
 
 // ===== Data Loading =====
 
+// Sections stream in as "dashboardSection" events, so fast sources show up
+// without waiting for the slowest. Events of an older fetch are ignored.
+let latestStreamId = 0;
+
+const sectionRenderers: Record<string, () => void> = {
+  weather: updateWeather,
+  airQuality: updateAirQuality,
+  meals: updateMeals,
+  events: updateEvents,
+  timetable: updateTimetable,
+  studyPlan: updateStudyPlan,
+};
+
+function renderAllSections(): void {
+  for (const render of Object.values(sectionRenderers)) render();
+}
+
+function listenForDashboardStream(): void {
+  window.runtime.EventsOn("dashboardSection", (id: number, section: string, data: unknown) => {
+    if (id < latestStreamId) return;
+    latestStreamId = id;
+    dashboardData = { ...(dashboardData ?? EMPTY_DASHBOARD), [section]: data };
+    sectionRenderers[section]?.();
+  });
+
+  window.runtime.EventsOn("dashboardDone", (id: number, done: DashboardDone) => {
+    if (id < latestStreamId || done.data.canceled) return;
+    latestStreamId = id;
+    dashboardData = done.data;
+    lastFetchTime = Date.now();
+    renderAllSections();
+  });
+}
+
 async function loadDashboardData(): Promise<void> {
   try {
-    dashboardData = await window.go.main.App.FetchDashboardData();
-    lastFetchTime = Date.now();
-    updateWeather();
-    updateAirQuality();
-    updateTimetable();
-    updateMeals();
-    updateEvents();
-    updateStudyPlan();
+    latestStreamId = Math.max(latestStreamId, await window.go.main.App.StreamDashboardData());
   } catch (err) {
     console.error("Failed to load dashboard data:", err);
   }
@@ -589,39 +666,49 @@ async function loadDashboardData(): Promise<void> {
 let alarmPopupTimeout: ReturnType<typeof setTimeout> | null = null;
 
 function showAlarmPopup(event: AlarmEvent): void {
-  const popup = document.getElementById("alarmPopup");
-  const iconEl = document.getElementById("alarmPopupIcon");
-  const textEl = document.getElementById("alarmPopupText");
-  if (!popup || !iconEl || !textEl) return;
-
-  if (alarmPopupTimeout) {
-    clearTimeout(alarmPopupTimeout);
-    alarmPopupTimeout = null;
-  }
-
-  popup.className = "alarm-popup";
-
   let icon: string;
   let text: string;
+  let cls: string;
 
   switch (event.type) {
     case "start":
       icon = "\uD83D\uDD14";
       text = `${event.period}교시 수업 시작입니다`;
-      popup.classList.add("alarm-start");
+      cls = "alarm-start";
       break;
     case "end":
       icon = "\u2705";
       text = `${event.period}교시 수업 종료입니다`;
-      popup.classList.add("alarm-end");
+      cls = "alarm-end";
       break;
     case "warning":
       icon = "\u26A0\uFE0F";
-      text = `${event.period}교시 수업 1분 전입니다`;
-      popup.classList.add("alarm-warning");
+      text = `${event.period}교시 수업 곧 시작합니다 (${event.time})`;
+      cls = "alarm-warning";
+      break;
+    case "extra":
+      icon = "\uD83D\uDD14";
+      text = event.label || `${event.time} 알림`;
+      cls = "alarm-start";
       break;
   }
 
+  showPopup(icon, text, cls);
+}
+
+// showPopup shows the alarm popup for five seconds.
+function showPopup(icon: string, text: string, cls: string): void {
+  const popup = document.getElementById("alarmPopup");
+  const iconEl = document.getElementById("alarmPopupIcon");
+  const textEl = document.getElementById("alarmPopupText");
+  if (!popup || !iconEl || !textEl) return;
+
+  if (alarmPopupTimeout) {
+    clearTimeout(alarmPopupTimeout);
+    alarmPopupTimeout = null;
+  }
+
+  popup.className = `alarm-popup ${cls}`;
   iconEl.textContent = icon;
   textEl.textContent = text;
 
@@ -642,14 +729,6 @@ function startUpdateLoop(): void {
   setInterval(() => {
     updateClock();
     updateTimetable();
-
-    const settings = getSettings();
-    const periods = getPeriods(dashboardData?.timetable ?? null);
-    const alarmEvent = checkAndPlayAlarms(periods, settings.alarmEnabled, settings.alarmSound, settings.customAlarmData);
-    if (alarmEvent) {
-      showAlarmPopup(alarmEvent);
-    }
-    resetAlarmsIfNewDay();
   }, 1000);
 
   setInterval(() => {
@@ -2,7 +2,8 @@
 // SPA routing: dashboard + settings overlay
 
 import { initDashboard } from "./dashboard/dashboard";
-import { initSettings, openSettings } from "./settings/settings";
+import { initSettings, openSettings, showSettingsRecovery } from "./settings/settings";
+import { promptPIN } from "./settings/pin";
 
 async function main(): Promise<void> {
   // Initialize dashboard
@@ -20,6 +21,17 @@ async function main(): Promise<void> {
   window.runtime.EventsOn("openSettings", () => {
     openSettings();
   });
+
+  // Closing the window or quitting from the tray needs the admin PIN
+  window.runtime.EventsOn("pinRequired", async (action: string) => {
+    if (!(await promptPIN())) return;
+    if (action === "close") window.go.main.App.CloseWindow();
+    if (action === "quit") window.go.main.App.QuitApp();
+  });
+
+  // Tell the user if settings.json was damaged and restored or reset
+  await showSettingsRecovery(await window.go.main.App.GetSettingsStatus());
+  window.runtime.EventsOn("settingsRecovered", showSettingsRecovery);
 }
 
 document.addEventListener("DOMContentLoaded", main);
@@ -0,0 +1,61 @@
+// ===== Admin PIN Prompt =====
+// Asks for the admin PIN before settings open, the window closes or the app quits.
+
+let pending: Promise<boolean> | null = null;
+
+// promptPIN shows the PIN dialog and resolves true once the admin session is
+// unlocked, or false when the dialog is cancelled.
+export function promptPIN(): Promise<boolean> {
+  if (pending) return pending;
+
+  const overlay = document.getElementById("pinOverlay")!;
+  const input = document.getElementById("pinInput") as HTMLInputElement;
+  const statusEl = document.getElementById("pinStatus")!;
+  const btnOk = document.getElementById("btnPinOk") as HTMLButtonElement;
+  const btnCancel = document.getElementById("btnPinCancel") as HTMLButtonElement;
+
+  input.value = "";
+  statusEl.textContent = "";
+  overlay.classList.add("visible");
+  input.focus();
+
+  pending = new Promise((resolve) => {
+    const finish = (unlocked: boolean) => {
+      overlay.classList.remove("visible");
+      btnOk.removeEventListener("click", submit);
+      btnCancel.removeEventListener("click", cancel);
+      input.removeEventListener("keydown", onKey);
+      pending = null;
+      resolve(unlocked);
+    };
+    const submit = async () => {
+      btnOk.disabled = true;
+      const err = await window.go.main.App.UnlockAdmin(input.value);
+      btnOk.disabled = false;
+      if (!err) {
+        finish(true);
+        return;
+      }
+      statusEl.textContent = err;
+      input.value = "";
+      input.focus();
+    };
+    const cancel = () => finish(false);
+    const onKey = (e: KeyboardEvent) => {
+      if (e.key === "Enter") submit();
+      if (e.key === "Escape") cancel();
+    };
+    btnOk.addEventListener("click", submit);
+    btnCancel.addEventListener("click", cancel);
+    input.addEventListener("keydown", onKey);
+  });
+  return pending;
+}
+
+// ensureUnlocked resolves true when no PIN is set or the session is unlocked,
+// prompting for the PIN otherwise.
+export async function ensureUnlocked(): Promise<boolean> {
+  const status = await window.go.main.App.GetPINStatus();
+  if (!status.enabled || status.unlocked) return true;
+  return promptPIN();
+}
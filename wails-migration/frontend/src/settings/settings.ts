@@ -1,7 +1,8 @@
 // ===== Settings Overlay Logic =====
 // Uses Wails bindings instead of Electrobun RPC
 
-import type { Settings, CustomBackground } from "../types";
+import type { Settings, CustomBackground, CustomSound, BellSounds, SettingsStatus } from "../types";
+import { ensureUnlocked } from "./pin";
 
 // ===== Background Presets =====
 
@@ -85,9 +86,47 @@ const PASTEL_COLORS: ColorPreset[] = [
 ];
 
 let selectedBackgroundId = "";
-let pendingCustomAlarmData = "";
-let pendingCustomAlarmName = "";
 let customBackgrounds: CustomBackground[] = [];
+let customSounds: CustomSound[] = [];
+// Last settings loaded from Go, so fields without a form control survive a save.
+let baseSettings: Settings | null = null;
+
+const SOUND_PRESETS: { id: string; label: string }[] = [
+  { id: "classic", label: "기본 (클래식)" },
+  { id: "chime", label: "차임벨" },
+  { id: "soft", label: "부드러운" },
+  { id: "digital", label: "디지털" },
+  { id: "melody", label: "멜로디" },
+];
+
+// Inputs disabled when an admin policy locks the settings key. The school
+// search also fills the codes and location, so it is locked with them.
+const LOCKED_FIELD_INPUTS: Record<string, string[]> = {
+  schoolName: ["schoolNameInput", "searchSchoolBtn"],
+  schoolCode: ["schoolNameInput", "searchSchoolBtn"],
+  officeCode: ["schoolNameInput", "searchSchoolBtn"],
+  latitude: ["schoolNameInput", "searchSchoolBtn"],
+  longitude: ["schoolNameInput", "searchSchoolBtn"],
+  grade: ["grade"],
+  classNum: ["classNum"],
+  spreadsheetUrl: ["spreadsheetUrl"],
+  icsSources: ["icsSources"],
+  useCustomApiKey: ["useCustomApiKey"],
+  customApiKey: ["customApiKey"],
+  alarmEnabled: ["alarmEnabled"],
+  updateChannel: ["updateChannel"],
+  proxyUrl: ["proxyUrl"],
+  proxyPacUrl: ["proxyPacUrl"],
+  noProxy: ["noProxy"],
+  caCertFiles: ["caCertFiles"],
+};
+
+const BELL_SOUND_SELECTS: { key: keyof BellSounds; id: string }[] = [
+  { key: "warning", id: "bellSoundWarning" },
+  { key: "start", id: "bellSoundStart" },
+  { key: "end", id: "bellSoundEnd" },
+  { key: "extra", id: "bellSoundExtra" },
+];
 
 // ===== DOM Helpers =====
 
@@ -109,9 +148,9 @@ async function applyWindowBackground(bgId: string): Promise<void> {
   } else if (bgId.startsWith("custom:")) {
     frame.style.removeProperty("--bg-color");
     const customId = bgId.slice(7);
-    const dataURL = await window.go.main.App.GetCustomBackgroundURL(customId);
-    if (dataURL) {
-      frame.style.setProperty("--bg-image", `url('${dataURL}')`);
+    const bgURL = await window.go.main.App.GetCustomBackgroundURL(customId);
+    if (bgURL) {
+      frame.style.setProperty("--bg-image", `url('${bgURL}')`);
     } else {
       frame.style.removeProperty("--bg-image");
     }
@@ -202,10 +241,10 @@ function renderBackgroundPicker(): void {
     thumb.dataset.bgId = customBgId;
     thumb.title = cb.name;
 
-    window.go.main.App.GetCustomBackgroundURL(cb.id).then((dataURL: string) => {
-      if (dataURL) {
+    window.go.main.App.GetCustomBackgroundThumbURL(cb.id).then((bgURL: string) => {
+      if (bgURL) {
         const img = document.createElement("img");
-        img.src = dataURL;
+        img.src = bgURL;
         img.alt = cb.name;
         img.style.cssText = "width:100%;height:100%;object-fit:cover;border-radius:inherit;display:block;";
         thumb.insertBefore(img, thumb.firstChild);
@@ -218,7 +257,11 @@ function renderBackgroundPicker(): void {
     deleteBtn.title = "삭제";
     deleteBtn.addEventListener("click", async (e) => {
       e.stopPropagation();
-      await window.go.main.App.RemoveCustomBackground(cb.id);
+      const err = await window.go.main.App.RemoveCustomBackground(cb.id);
+      if (err) {
+        showStatus(err, "error");
+        return;
+      }
       customBackgrounds = customBackgrounds.filter((b) => b.id !== cb.id);
       if (selectedBackgroundId === customBgId) {
         selectedBackgroundId = "";
@@ -250,11 +293,17 @@ function renderBackgroundPicker(): void {
         id: result.id,
         name: result.name,
         fileName: result.fileName,
+        thumbFileName: result.thumbFileName,
+        hash: result.hash,
       };
-      customBackgrounds.push(newBg);
+      // The same photo imported twice comes back with the existing ID.
+      if (!customBackgrounds.some((b) => b.id === newBg.id)) {
+        customBackgrounds.push(newBg);
+      }
       selectedBackgroundId = `custom:${newBg.id}`;
       const values = collectFormValues();
-      await window.go.main.App.SaveSettings(values);
+      const err = await window.go.main.App.SaveSettings(values);
+      if (err) showStatus(err, "error");
       renderBackgroundPicker();
       applyWindowBackground(selectedBackgroundId);
     }
@@ -296,6 +345,7 @@ function loadFormValues(s: Settings): void {
   ($("latitude") as HTMLInputElement).value = String(s.latitude);
   ($("longitude") as HTMLInputElement).value = String(s.longitude);
   $("spreadsheetUrl").value = s.spreadsheetUrl;
+  $("icsSources").value = (s.icsSources || []).join("; ");
 
   // API key toggle
   const useCustomKey = s.useCustomApiKey || false;
@@ -305,11 +355,19 @@ function loadFormValues(s: Settings): void {
   if (apiKeyGroup) apiKeyGroup.style.display = useCustomKey ? "" : "none";
 
   ($("alarmEnabled") as HTMLInputElement).checked = s.alarmEnabled;
-
+  $("updateChannel").value = s.updateChannel || "stable";
+  $("proxyUrl").value = s.proxyUrl || "";
+  $("proxyPacUrl").value = s.proxyPacUrl || "";
+  $("noProxy").value = s.noProxy || "";
+  $("caCertFiles").value = (s.caCertFiles || []).join("; ");
+
+  baseSettings = s;
+  applyLockedFields(s.lockedFields || []);
+  customSounds = s.customSounds || [];
+  renderCustomSounds();
   const radio = document.querySelector(`input[name="alarmSound"][value="${s.alarmSound || "classic"}"]`) as HTMLInputElement | null;
   if (radio) radio.checked = true;
-
-  updateCustomAlarmDisplay(s.customAlarmName, s.customAlarmData);
+  renderBellSoundSelects(s.bellSounds);
 
   customBackgrounds = s.customBackgrounds || [];
   selectedBackgroundId = s.backgroundId || "";
@@ -317,9 +375,35 @@ function loadFormValues(s: Settings): void {
   renderBackgroundPicker();
 }
 
+function applyLockedFields(locked: string[]): void {
+  const disabled = new Set(locked.flatMap((key) => LOCKED_FIELD_INPUTS[key] || []));
+  for (const id of new Set(Object.values(LOCKED_FIELD_INPUTS).flat())) {
+    const el = document.getElementById(id) as HTMLInputElement | HTMLButtonElement | null;
+    if (!el) continue;
+    el.disabled = disabled.has(id);
+    el.title = el.disabled ? "관리자 정책으로 잠긴 설정입니다" : "";
+  }
+}
+
+// withLockedFields restores the policy values of locked keys, which the
+// backend refuses to change.
+function withLockedFields(values: Settings): Settings {
+  const locked = baseSettings?.lockedFields || [];
+  const result: Record<string, unknown> = { ...values };
+  for (const key of locked) {
+    result[key] = (baseSettings as unknown as Record<string, unknown>)[key];
+  }
+  return result as unknown as Settings;
+}
+
+// collectFormValues returns the form on top of the settings it was loaded
+// from. Fields the backend changes while the window is open (bell mute,
+// countdowns, background cleanup) go back as loaded; SaveSettings keeps its
+// current values for those.
 function collectFormValues(): Settings {
   const selectedRadio = document.querySelector('input[name="alarmSound"]:checked') as HTMLInputElement | null;
-  return {
+  return withLockedFields({
+    ...baseSettings,
     schoolName: $("schoolNameInput").value.trim(),
     schoolCode: $("schoolCode").value.trim(),
     officeCode: $("officeCode").value.trim(),
@@ -328,43 +412,147 @@ function collectFormValues(): Settings {
     latitude: parseFloat(($("latitude") as HTMLInputElement).value) || 0,
     longitude: parseFloat(($("longitude") as HTMLInputElement).value) || 0,
     spreadsheetUrl: $("spreadsheetUrl").value.trim(),
+    icsSources: $("icsSources").value.split(";").map((src) => src.trim()).filter(Boolean),
     useCustomApiKey: ($("useCustomApiKey") as HTMLInputElement).checked,
     customApiKey: $("customApiKey").value.trim(),
     alarmEnabled: ($("alarmEnabled") as HTMLInputElement).checked,
     alarmSound: selectedRadio?.value || "classic",
-    customAlarmData: pendingCustomAlarmData,
-    customAlarmName: pendingCustomAlarmName,
+    bellSounds: collectBellSounds(),
+    customSounds: customSounds,
     backgroundId: selectedBackgroundId,
     customBackgrounds: customBackgrounds,
-  };
+    updateChannel: $("updateChannel").value,
+    proxyUrl: $("proxyUrl").value.trim(),
+    proxyPacUrl: $("proxyPacUrl").value.trim(),
+    noProxy: $("noProxy").value.trim(),
+    caCertFiles: $("caCertFiles").value.split(";").map((f) => f.trim()).filter(Boolean),
+  });
 }
 
-function updateCustomAlarmDisplay(name: string, data: string): void {
-  const infoEl = document.getElementById("customAlarmInfo");
-  const nameEl = document.getElementById("customAlarmName");
-  if (!infoEl || !nameEl) return;
+// ===== Alarm Sounds =====
 
-  pendingCustomAlarmData = data || "";
-  pendingCustomAlarmName = name || "";
+async function previewCustomSound(id: string): Promise<void> {
+  const soundURL = await window.go.main.App.GetCustomSoundURL(id);
+  if (soundURL) {
+    const audio = new Audio(soundURL);
+    audio.volume = 0.5;
+    audio.play().catch(() => { });
+  }
+}
 
-  if (name) {
-    nameEl.textContent = name;
-    infoEl.style.display = "flex";
-  } else {
-    infoEl.style.display = "none";
+function renderCustomSounds(): void {
+  const list = document.getElementById("customSoundList");
+  if (!list) return;
+  const checked = (document.querySelector('input[name="alarmSound"]:checked') as HTMLInputElement | null)?.value;
+  list.innerHTML = "";
+
+  for (const cs of customSounds) {
+    const value = `custom:${cs.id}`;
+    const item = document.createElement("label");
+    item.className = "alarm-preset-item alarm-preset-custom";
+    item.title = cs.name;
+
+    const radio = document.createElement("input");
+    radio.type = "radio";
+    radio.name = "alarmSound";
+    radio.value = value;
+    radio.checked = checked === value;
+
+    const name = document.createElement("span");
+    name.className = "alarm-preset-name custom-alarm-filename";
+    name.textContent = cs.name;
+
+    const previewBtn = document.createElement("button");
+    previewBtn.type = "button";
+    previewBtn.className = "btn-preview";
+    previewBtn.title = "미리듣기";
+    previewBtn.innerHTML = "&#9654;";
+    previewBtn.addEventListener("click", (e) => {
+      e.preventDefault();
+      e.stopPropagation();
+      previewCustomSound(cs.id);
+    });
+
+    const deleteBtn = document.createElement("button");
+    deleteBtn.type = "button";
+    deleteBtn.className = "btn-preview";
+    deleteBtn.title = "삭제";
+    deleteBtn.innerHTML = "\u00D7";
+    deleteBtn.addEventListener("click", async (e) => {
+      e.preventDefault();
+      e.stopPropagation();
+      const err = await window.go.main.App.RemoveCustomSound(cs.id);
+      if (err) {
+        showStatus(err, "error");
+        return;
+      }
+      const reloaded = await window.go.main.App.GetSettings();
+      baseSettings = reloaded;
+      customSounds = reloaded.customSounds || [];
+      renderCustomSounds();
+      const radio = document.querySelector(`input[name="alarmSound"][value="${reloaded.alarmSound}"]`) as HTMLInputElement | null;
+      if (radio) radio.checked = true;
+      renderBellSoundSelects(reloaded.bellSounds);
+    });
+
+    item.append(radio, name, previewBtn, deleteBtn);
+    list.appendChild(item);
+  }
+}
+
+function renderBellSoundSelects(selected: BellSounds | undefined): void {
+  for (const { key, id } of BELL_SOUND_SELECTS) {
+    const select = document.getElementById(id) as HTMLSelectElement | null;
+    if (!select) continue;
+    const current = selected?.[key] ?? select.value;
+    select.innerHTML = "";
+    select.add(new Option("기본 알림음 사용", ""));
+    for (const p of SOUND_PRESETS) {
+      select.add(new Option(p.label, p.id));
+    }
+    for (const cs of customSounds) {
+      select.add(new Option(cs.name, `custom:${cs.id}`));
+    }
+    select.value = current;
+    if (select.value !== current) select.value = "";
+  }
+}
+
+function collectBellSounds(): BellSounds {
+  const sounds: BellSounds = { warning: "", start: "", end: "", extra: "" };
+  for (const { key, id } of BELL_SOUND_SELECTS) {
+    const select = document.getElementById(id) as HTMLSelectElement | null;
+    if (select) sounds[key] = select.value;
   }
+  return sounds;
+}
+
+// ===== API Usage =====
+
+async function renderAPIUsage(): Promise<void> {
+  const el = document.getElementById("apiUsage");
+  if (!el) return;
+  const usage = await window.go.main.App.GetAPIUsage();
+  let text = `오늘 NEIS API 호출: ${usage.calls}회`;
+  if (usage.customKey) {
+    text += ` / 하루 ${usage.limit}회`;
+    if (usage.exceeded) text += " — 오늘 한도를 초과했습니다";
+    else if (usage.warning) text += " — 한도에 가까워지고 있습니다";
+  }
+  el.textContent = text;
+  el.className = usage.warning ? "update-status error" : "update-status";
 }
 
 // ===== Status Message =====
 
-function showStatus(message: string, type: "success" | "error"): void {
+function showStatus(message: string, type: "success" | "error", duration: number = 3000): void {
   const el = document.getElementById("statusMessage")!;
   el.textContent = message;
   el.className = `status-message ${type}`;
   el.style.display = "block";
   setTimeout(() => {
     el.style.display = "none";
-  }, 3000);
+  }, duration);
 }
 
 // ===== Search Results =====
@@ -529,14 +717,18 @@ export async function initSettings(): Promise<void> {
   const autoStartCheckbox = document.getElementById("autoStart") as HTMLInputElement;
   if (autoStartCheckbox) {
     autoStartCheckbox.checked = await window.go.main.App.GetAutoStart();
-    autoStartCheckbox.addEventListener("change", () => {
-      window.go.main.App.SetAutoStart(autoStartCheckbox.checked);
+    autoStartCheckbox.addEventListener("change", async () => {
+      const err = await window.go.main.App.SetAutoStart(autoStartCheckbox.checked);
+      if (err) {
+        autoStartCheckbox.checked = !autoStartCheckbox.checked;
+        showStatus(err, "error");
+      }
     });
   }
 
   // Close settings overlay
   document.getElementById("btnCloseSettings")?.addEventListener("click", () => {
-    document.getElementById("settingsOverlay")?.classList.remove("open");
+    closeSettings();
   });
 
   // Help modal (spreadsheet)
@@ -558,6 +750,20 @@ export async function initSettings(): Promise<void> {
     if (customApiKeyGroup) customApiKeyGroup.style.display = useCustomApiKeyCheckbox.checked ? "" : "none";
   });
 
+  // API key check
+  document.getElementById("btnValidateApiKey")?.addEventListener("click", async () => {
+    const btn = document.getElementById("btnValidateApiKey") as HTMLButtonElement;
+    const statusEl = document.getElementById("apiKeyStatus")!;
+    btn.disabled = true;
+    statusEl.className = "update-status";
+    statusEl.textContent = "확인 중...";
+    const result = await window.go.main.App.ValidateAPIKey($("customApiKey").value);
+    statusEl.textContent = result.message;
+    statusEl.className = result.status === "valid" ? "update-status available" : "update-status error";
+    btn.disabled = false;
+    renderAPIUsage();
+  });
+
   // API key help modal
   const apiKeyHelpOverlay = document.getElementById("apiKeyHelpOverlay")!;
   document.getElementById("btnApiKeyHelp")?.addEventListener("click", (e) => {
@@ -617,20 +823,15 @@ export async function initSettings(): Promise<void> {
     e.stopPropagation();
     const result = await window.go.main.App.PickAlarmFile();
     if (result) {
-      updateCustomAlarmDisplay(result.name, result.data);
-      const customRadio = document.querySelector('input[name="alarmSound"][value="custom"]') as HTMLInputElement;
+      const bellSounds = collectBellSounds();
+      customSounds.push({ id: result.id, name: result.name, fileName: result.fileName });
+      renderCustomSounds();
+      const customRadio = document.querySelector(`input[name="alarmSound"][value="custom:${result.id}"]`) as HTMLInputElement | null;
       if (customRadio) customRadio.checked = true;
-    }
-  });
-
-  // Custom alarm preview
-  document.getElementById("btnPreviewCustom")?.addEventListener("click", (e) => {
-    e.preventDefault();
-    e.stopPropagation();
-    if (pendingCustomAlarmData) {
-      const audio = new Audio(pendingCustomAlarmData);
-      audio.volume = 0.5;
-      audio.play().catch(() => { });
+      renderBellSoundSelects(bellSounds);
+      // Save right away so the imported file is never left without an entry.
+      const err = await window.go.main.App.SaveSettings(collectFormValues());
+      if (err) showStatus(err, "error");
     }
   });
 
@@ -641,6 +842,51 @@ export async function initSettings(): Promise<void> {
     versionLabel.textContent = `v${ver}`;
   }
 
+  // Admin PIN
+  const pinStatus = document.getElementById("adminPinStatus")!;
+  const pinInput = document.getElementById("adminPinInput") as HTMLInputElement;
+  const setAdminPIN = async (pin: string, done: string) => {
+    const err = await window.go.main.App.SetAdminPIN(pin);
+    pinInput.value = "";
+    pinStatus.textContent = err || done;
+    pinStatus.className = err ? "update-status error" : "update-status latest";
+  };
+  document.getElementById("btnSetAdminPin")?.addEventListener("click", () => {
+    setAdminPIN(pinInput.value.trim(), "PIN이 설정되었습니다");
+  });
+  document.getElementById("btnClearAdminPin")?.addEventListener("click", () => {
+    if (confirm("관리자 PIN을 해제하시겠습니까?")) {
+      setAdminPIN("", "PIN이 해제되었습니다");
+    }
+  });
+
+  // Settings bundle export/import
+  const bundleStatus = document.getElementById("bundleStatus")!;
+  document.getElementById("btnExportSettings")?.addEventListener("click", async () => {
+    bundleStatus.className = "update-status";
+    bundleStatus.textContent = "";
+    const includeSecrets = (document.getElementById("exportIncludeSecrets") as HTMLInputElement).checked;
+    const err = await window.go.main.App.ExportSettings(includeSecrets);
+    if (err) {
+      bundleStatus.textContent = err;
+      bundleStatus.className = "update-status error";
+    }
+  });
+  document.getElementById("btnImportSettings")?.addEventListener("click", async () => {
+    bundleStatus.className = "update-status";
+    bundleStatus.textContent = "";
+    const err = await window.go.main.App.ImportSettings({
+      keepClass: (document.getElementById("importKeepClass") as HTMLInputElement).checked,
+      keepApiKey: (document.getElementById("importKeepApiKey") as HTMLInputElement).checked,
+    });
+    if (err) {
+      bundleStatus.textContent = err;
+      bundleStatus.className = "update-status error";
+      return;
+    }
+    loadFormValues(await window.go.main.App.GetSettings());
+  });
+
   // Manual update check
   let latestDownloadURL = "";
   document.getElementById("btnCheckUpdate")?.addEventListener("click", async () => {
@@ -704,32 +950,23 @@ export async function initSettings(): Promise<void> {
   // Save (uses Go backend)
   document.getElementById("saveBtn")!.addEventListener("click", async () => {
     const values = collectFormValues();
-    await window.go.main.App.SaveSettings(values);
+    const err = await window.go.main.App.SaveSettings(values);
+    if (err) {
+      showStatus(err, "error");
+      return;
+    }
     showStatus("설정이 저장되었습니다", "success");
   });
 
   // Reset
   document.getElementById("resetBtn")!.addEventListener("click", async () => {
     if (confirm("모든 설정을 초기값으로 되돌리시겠습니까?")) {
-      const defaultSettings: Settings = {
-        schoolName: "",
-        schoolCode: "",
-        officeCode: "",
-        grade: 0,
-        classNum: 0,
-        latitude: 0,
-        longitude: 0,
-        spreadsheetUrl: "",
-        useCustomApiKey: false,
-        customApiKey: "",
-        alarmEnabled: true,
-        alarmSound: "classic",
-        customAlarmData: "",
-        customAlarmName: "",
-        backgroundId: "",
-        customBackgrounds: [],
-      };
-      await window.go.main.App.SaveSettings(defaultSettings);
+      const defaultSettings = await window.go.main.App.GetDefaultSettings();
+      const err = await window.go.main.App.SaveSettings(withLockedFields(defaultSettings));
+      if (err) {
+        showStatus(err, "error");
+        return;
+      }
       const reloaded = await window.go.main.App.GetSettings();
       loadFormValues(reloaded);
       showStatus("설정이 초기화되었습니다", "success");
@@ -737,12 +974,32 @@ export async function initSettings(): Promise<void> {
   });
 }
 
+// ===== Recovery Notice =====
+
+// showSettingsRecovery opens the overlay with the recovered values and tells
+// the user that settings.json was damaged.
+export async function showSettingsRecovery(status: SettingsStatus): Promise<void> {
+  if (!status.recovered && !status.reset) return;
+  loadFormValues(await window.go.main.App.GetSettings());
+  if (!(await openSettings())) return;
+  const message = status.recovered
+    ? `설정 파일이 손상되어 백업(${status.backupFile})에서 복구했습니다. 설정을 확인하세요.`
+    : "설정 파일이 손상되어 기본값으로 초기화되었습니다. 설정을 다시 입력하세요.";
+  showStatus(message, "error", 10000);
+}
+
 // ===== Toggle =====
 
-export function openSettings(): void {
+// openSettings asks for the admin PIN first when one is set, and reports
+// whether the overlay opened.
+export async function openSettings(): Promise<boolean> {
+  if (!(await ensureUnlocked())) return false;
   document.getElementById("settingsOverlay")?.classList.add("open");
+  renderAPIUsage();
+  return true;
 }
 
 export function closeSettings(): void {
   document.getElementById("settingsOverlay")?.classList.remove("open");
+  window.go.main.App.LockAdmin();
 }
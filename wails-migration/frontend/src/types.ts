@@ -9,20 +9,42 @@ export interface Settings {
   latitude: number;
   longitude: number;
   spreadsheetUrl: string;
+  icsSources?: string[];
   useCustomApiKey: boolean;
   customApiKey: string;
   alarmEnabled: boolean;
   alarmSound: string;
-  customAlarmData: string;
-  customAlarmName: string;
+  bellSounds: BellSounds;
+  customSounds: CustomSound[];
   backgroundId: string;
   customBackgrounds: CustomBackground[];
+  updateChannel?: string;
+  proxyUrl?: string; // the password comes back masked
+  proxyPacUrl?: string;
+  noProxy?: string;
+  caCertFiles?: string[];
+  lockedFields?: string[]; // set by GetSettings when an admin policy applies
+}
+
+export interface BellSounds {
+  warning: string;
+  start: string;
+  end: string;
+  extra: string;
+}
+
+export interface CustomSound {
+  id: string;
+  name: string;
+  fileName: string;
 }
 
 export interface CustomBackground {
   id: string;
   name: string;
   fileName: string;
+  thumbFileName?: string;
+  hash?: string;
 }
 
 export interface WeatherData {
@@ -80,6 +102,38 @@ export interface DashboardData {
   events: ScheduleEvent[];
   timetable: TimetableData | null;
   studyPlan: StudyPlanResult | null;
+  sources: Record<string, SourceStatus>;
+  canceled?: boolean;
+}
+
+// A teacher's own calendar item; sent with the "reminder" event when its
+// remindAt time arrives.
+export interface PersonalEvent {
+  id: string;
+  date: string; // YYYYMMDD
+  endDate?: string;
+  name: string;
+  detail?: string;
+  remindAt?: string; // "2006-01-02T15:04"
+  reminded?: boolean;
+}
+
+export interface DashboardDone {
+  data: DashboardData;
+  elapsedMs: number;
+}
+
+export interface NEISKeyStatus {
+  label: "personal" | "builtin";
+  status: "ok" | "invalid" | "quota";
+  until?: string;
+}
+
+export interface SourceStatus {
+  status: "ok" | "error" | "skipped";
+  error?: string;
+  key?: NEISKeyStatus;
+  elapsedMs?: number;
 }
 
 export interface SchoolInfo {
@@ -95,14 +149,50 @@ export interface Coords {
 }
 
 export interface AlarmFileResult {
-  data: string;
+  id: string;
   name: string;
+  fileName: string;
 }
 
 export interface BackgroundFileResult {
   id: string;
   name: string;
   fileName: string;
+  thumbFileName?: string;
+  hash?: string;
+}
+
+export interface ImportOptions {
+  keepClass: boolean;
+  keepApiKey: boolean;
+}
+
+export interface APIUsage {
+  date: string;
+  calls: number;
+  limit: number;
+  customKey: boolean;
+  warning: boolean;
+  exceeded: boolean;
+}
+
+export interface APIKeyValidation {
+  status: "valid" | "invalid" | "quota" | "network" | "error";
+  message: string;
+}
+
+export interface PINStatus {
+  enabled: boolean;
+  unlocked: boolean;
+  retryAfter: number;
+}
+
+export interface SettingsStatus {
+  recovered: boolean;
+  reset: boolean;
+  backupFile?: string;
+  corruptFile?: string;
+  time: string;
 }
 
 export type AirQualityLevel = "good" | "moderate" | "unhealthy" | "very-unhealthy";
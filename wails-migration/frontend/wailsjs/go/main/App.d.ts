@@ -2,25 +2,63 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function AddCountdown(arg1:string,arg2:string):Promise<string>;
+
+export function AddPersonalEvent(arg1:main.PersonalEvent):Promise<main.PersonalEventResult>;
+
+export function CancelDashboardFetch():Promise<void>;
+
 export function CheckForUpdate():Promise<main.UpdateCheckResult>;
 
+export function CleanupBackgrounds(arg1:boolean):Promise<main.BackgroundMaintenanceReport>;
+
 export function CloseWindow():Promise<void>;
 
+export function DeletePersonalEvent(arg1:string):Promise<string>;
+
 export function DownloadAndRunUpdate(arg1:string):Promise<string>;
 
+export function ExportCalendar(arg1:boolean,arg2:boolean):Promise<string>;
+
+export function ExportSettings(arg1:boolean):Promise<string>;
+
 export function FetchDashboardData():Promise<main.DashboardData>;
 
 export function GeocodeAddress(arg1:string):Promise<main.Coords>;
 
+export function GetAPIUsage():Promise<main.APIUsage>;
+
+export function GetActiveBackground():Promise<string>;
+
 export function GetAppVersion():Promise<string>;
 
 export function GetAutoStart():Promise<boolean>;
 
+export function GetBellMuteStatus():Promise<main.BellMuteStatus>;
+
+export function GetCountdowns():Promise<Array<main.CountdownStatus>>;
+
+export function GetCustomBackgroundThumbURL(arg1:string):Promise<string>;
+
 export function GetCustomBackgroundURL(arg1:string):Promise<string>;
 
-export function GetNeisAPIKey():Promise<string>;
+export function GetCustomSoundURL(arg1:string):Promise<string>;
+
+export function GetDefaultSettings():Promise<main.Settings>;
+
+export function GetPINStatus():Promise<main.PINStatus>;
+
+export function GetPersonalEvents():Promise<Array<main.PersonalEvent>>;
 
-export function GetSettings():Promise<main.Settings>;
+export function GetSettings():Promise<main.SettingsView>;
+
+export function GetSettingsStatus():Promise<main.SettingsStatus>;
+
+export function GetTodayBells():Promise<Array<main.Bell>>;
+
+export function ImportSettings(arg1:main.ImportOptions):Promise<string>;
+
+export function LockAdmin():Promise<void>;
 
 export function MaximizeWindow():Promise<void>;
 
@@ -32,10 +70,30 @@ export function PickAlarmFile():Promise<main.AlarmFileResult>;
 
 export function PickBackgroundFile():Promise<main.BackgroundFileResult>;
 
-export function RemoveCustomBackground(arg1:string):Promise<void>;
+export function PinEventCountdown(arg1:main.ScheduleEvent):Promise<string>;
+
+export function QuitApp():Promise<void>;
+
+export function RemoveCountdown(arg1:string):Promise<string>;
+
+export function RemoveCustomBackground(arg1:string):Promise<string>;
 
-export function SaveSettings(arg1:main.Settings):Promise<void>;
+export function RemoveCustomSound(arg1:string):Promise<string>;
+
+export function SaveSettings(arg1:main.Settings):Promise<string>;
 
 export function SearchSchool(arg1:string):Promise<main.SchoolSearchResult>;
 
-export function SetAutoStart(arg1:boolean):Promise<void>;
+export function SetAdminPIN(arg1:string):Promise<string>;
+
+export function SetAutoStart(arg1:boolean):Promise<string>;
+
+export function SetBellsMutedToday(arg1:boolean):Promise<void>;
+
+export function StreamDashboardData():Promise<number>;
+
+export function UnlockAdmin(arg1:string):Promise<string>;
+
+export function UpdatePersonalEvent(arg1:main.PersonalEvent):Promise<main.PersonalEventResult>;
+
+export function ValidateAPIKey(arg1:string):Promise<main.APIKeyValidation>;
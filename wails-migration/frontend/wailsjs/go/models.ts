@@ -1,5 +1,41 @@
 export namespace main {
 	
+	export class APIKeyValidation {
+	    status: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new APIKeyValidation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.message = source["message"];
+	    }
+	}
+	export class APIUsage {
+	    date: string;
+	    calls: number;
+	    limit: number;
+	    customKey: boolean;
+	    warning: boolean;
+	    exceeded: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new APIUsage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.calls = source["calls"];
+	        this.limit = source["limit"];
+	        this.customKey = source["customKey"];
+	        this.warning = source["warning"];
+	        this.exceeded = source["exceeded"];
+	    }
+	}
 	export class AirQualityData {
 	    pm10: number;
 	    pm25: number;
@@ -15,8 +51,9 @@ export namespace main {
 	    }
 	}
 	export class AlarmFileResult {
-	    data: string;
+	    id: string;
 	    name: string;
+	    fileName: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new AlarmFileResult(source);
@@ -24,14 +61,17 @@ export namespace main {
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.data = source["data"];
+	        this.id = source["id"];
 	        this.name = source["name"];
+	        this.fileName = source["fileName"];
 	    }
 	}
 	export class BackgroundFileResult {
 	    id: string;
 	    name: string;
 	    fileName: string;
+	    thumbFileName?: string;
+	    hash?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new BackgroundFileResult(source);
@@ -42,6 +82,216 @@ export namespace main {
 	        this.id = source["id"];
 	        this.name = source["name"];
 	        this.fileName = source["fileName"];
+	        this.thumbFileName = source["thumbFileName"];
+	        this.hash = source["hash"];
+	    }
+	}
+	export class BackgroundMaintenanceReport {
+	    adopted: number;
+	    deletedFiles: number;
+	    droppedEntries: number;
+	    freedBytes: number;
+	    errors?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BackgroundMaintenanceReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.adopted = source["adopted"];
+	        this.deletedFiles = source["deletedFiles"];
+	        this.droppedEntries = source["droppedEntries"];
+	        this.freedBytes = source["freedBytes"];
+	        this.errors = source["errors"];
+	    }
+	}
+	export class BackgroundRotation {
+	    mode: string;
+	    interval: number;
+	    images: string[];
+	    months: string[];
+	    rainy: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackgroundRotation(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.interval = source["interval"];
+	        this.images = source["images"];
+	        this.months = source["months"];
+	        this.rainy = source["rainy"];
+	    }
+	}
+	export class Bell {
+	    period: number;
+	    type: string;
+	    tone: string;
+	    label?: string;
+	    sound?: string;
+	    time: string;
+	    // Go type: time
+	    at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Bell(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.period = source["period"];
+	        this.type = source["type"];
+	        this.tone = source["tone"];
+	        this.label = source["label"];
+	        this.sound = source["sound"];
+	        this.time = source["time"];
+	        this.at = this.convertValues(source["at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExtraBell {
+	    id: string;
+	    time: string;
+	    label: string;
+	    tone: string;
+	    weekdays?: number[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ExtraBell(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.time = source["time"];
+	        this.label = source["label"];
+	        this.tone = source["tone"];
+	        this.weekdays = source["weekdays"];
+	    }
+	}
+	export class PeriodBellOverride {
+	    period: number;
+	    warning?: boolean;
+	    start?: boolean;
+	    end?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PeriodBellOverride(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.period = source["period"];
+	        this.warning = source["warning"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	    }
+	}
+	export class BellRule {
+	    enabled: boolean;
+	    offset: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BellRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.offset = source["offset"];
+	    }
+	}
+	export class BellConfig {
+	    warning: BellRule;
+	    start: BellRule;
+	    end: BellRule;
+	    periods: PeriodBellOverride[];
+	    extra: ExtraBell[];
+	
+	    static createFrom(source: any = {}) {
+	        return new BellConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.warning = this.convertValues(source["warning"], BellRule);
+	        this.start = this.convertValues(source["start"], BellRule);
+	        this.end = this.convertValues(source["end"], BellRule);
+	        this.periods = this.convertValues(source["periods"], PeriodBellOverride);
+	        this.extra = this.convertValues(source["extra"], ExtraBell);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BellMuteStatus {
+	    muted: boolean;
+	    manual: boolean;
+	    reason: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BellMuteStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.muted = source["muted"];
+	        this.manual = source["manual"];
+	        this.reason = source["reason"];
+	    }
+	}
+	
+	export class BellSounds {
+	    warning: string;
+	    start: string;
+	    end: string;
+	    extra: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new BellSounds(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.warning = source["warning"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.extra = source["extra"];
 	    }
 	}
 	export class Coords {
@@ -58,10 +308,54 @@ export namespace main {
 	        this.lon = source["lon"];
 	    }
 	}
+	export class Countdown {
+	    id: string;
+	    name: string;
+	    date: string;
+	    source?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Countdown(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.date = source["date"];
+	        this.source = source["source"];
+	    }
+	}
+	export class CountdownStatus {
+	    id: string;
+	    name: string;
+	    date: string;
+	    source?: string;
+	    label: string;
+	    daysLeft: number;
+	    schoolDaysLeft: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new CountdownStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.date = source["date"];
+	        this.source = source["source"];
+	        this.label = source["label"];
+	        this.daysLeft = source["daysLeft"];
+	        this.schoolDaysLeft = source["schoolDaysLeft"];
+	    }
+	}
 	export class CustomBackground {
 	    id: string;
 	    name: string;
 	    fileName: string;
+	    thumbFileName?: string;
+	    hash?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new CustomBackground(source);
@@ -72,7 +366,96 @@ export namespace main {
 	        this.id = source["id"];
 	        this.name = source["name"];
 	        this.fileName = source["fileName"];
+	        this.thumbFileName = source["thumbFileName"];
+	        this.hash = source["hash"];
+	    }
+	}
+	export class CustomSound {
+	    id: string;
+	    name: string;
+	    fileName: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CustomSound(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.fileName = source["fileName"];
+	    }
+	}
+	export class NEISKeyStatus {
+	    label: string;
+	    status: string;
+	    // Go type: time
+	    until?: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new NEISKeyStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.label = source["label"];
+	        this.status = source["status"];
+	        this.until = this.convertValues(source["until"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SourceStatus {
+	    status: string;
+	    error?: string;
+	    key?: NEISKeyStatus;
+	    elapsedMs?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SourceStatus(source);
 	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.status = source["status"];
+	        this.error = source["error"];
+	        this.key = this.convertValues(source["key"], NEISKeyStatus);
+	        this.elapsedMs = source["elapsedMs"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class StudyPlanBlock {
 	    title: string;
@@ -174,8 +557,13 @@ export namespace main {
 	}
 	export class ScheduleEvent {
 	    date: string;
+	    endDate?: string;
 	    name: string;
 	    detail?: string;
+	    source?: string;
+	    sources?: string[];
+	    holiday?: boolean;
+	    uid?: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new ScheduleEvent(source);
@@ -184,8 +572,13 @@ export namespace main {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.date = source["date"];
+	        this.endDate = source["endDate"];
 	        this.name = source["name"];
 	        this.detail = source["detail"];
+	        this.source = source["source"];
+	        this.sources = source["sources"];
+	        this.holiday = source["holiday"];
+	        this.uid = source["uid"];
 	    }
 	}
 	export class MealData {
@@ -231,6 +624,8 @@ export namespace main {
 	    events: ScheduleEvent[];
 	    timetable?: TimetableData;
 	    studyPlan?: StudyPlanResult;
+	    sources: Record<string, SourceStatus>;
+	    canceled?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new DashboardData(source);
@@ -244,6 +639,8 @@ export namespace main {
 	        this.events = this.convertValues(source["events"], ScheduleEvent);
 	        this.timetable = this.convertValues(source["timetable"], TimetableData);
 	        this.studyPlan = this.convertValues(source["studyPlan"], StudyPlanResult);
+	        this.sources = this.convertValues(source["sources"], SourceStatus, true);
+	        this.canceled = source["canceled"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -265,7 +662,96 @@ export namespace main {
 		}
 	}
 	
+	export class ImportOptions {
+	    keepClass: boolean;
+	    keepApiKey: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportOptions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.keepClass = source["keepClass"];
+	        this.keepApiKey = source["keepApiKey"];
+	    }
+	}
+	
 	
+	export class PINStatus {
+	    enabled: boolean;
+	    unlocked: boolean;
+	    retryAfter: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new PINStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.unlocked = source["unlocked"];
+	        this.retryAfter = source["retryAfter"];
+	    }
+	}
+	
+	
+	export class PersonalEvent {
+	    id: string;
+	    date: string;
+	    endDate?: string;
+	    name: string;
+	    detail?: string;
+	    remindAt?: string;
+	    reminded?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new PersonalEvent(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.date = source["date"];
+	        this.endDate = source["endDate"];
+	        this.name = source["name"];
+	        this.detail = source["detail"];
+	        this.remindAt = source["remindAt"];
+	        this.reminded = source["reminded"];
+	    }
+	}
+	export class PersonalEventResult {
+	    event: PersonalEvent;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new PersonalEventResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.event = this.convertValues(source["event"], PersonalEvent);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
 	
 	export class SchoolInfo {
 	    schoolCode: string;
@@ -288,17 +774,17 @@ export namespace main {
 	export class SchoolSearchResult {
 	    schools: SchoolInfo[];
 	    error: string;
-
+	
 	    static createFrom(source: any = {}) {
 	        return new SchoolSearchResult(source);
 	    }
-
+	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.schools = this.convertValues(source["schools"], SchoolInfo);
 	        this.error = source["error"];
 	    }
-
+	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
 		    if (!a) {
 		        return a;
@@ -318,6 +804,7 @@ export namespace main {
 		}
 	}
 	export class Settings {
+	    schemaVersion: number;
 	    schoolName: string;
 	    schoolCode: string;
 	    officeCode: string;
@@ -326,21 +813,163 @@ export namespace main {
 	    latitude: number;
 	    longitude: number;
 	    spreadsheetUrl: string;
+	    icsSources: string[];
+	    eventLimit: number;
+	    eventWindowDays: number;
 	    useCustomApiKey: boolean;
 	    customApiKey: string;
 	    alarmEnabled: boolean;
 	    alarmSound: string;
-	    customAlarmData: string;
-	    customAlarmName: string;
+	    bellSounds: BellSounds;
+	    customSounds: CustomSound[];
+	    bells: BellConfig;
+	    muteBellsDate: string;
 	    backgroundId: string;
+	    backgroundRotation: BackgroundRotation;
 	    customBackgrounds: CustomBackground[];
-
+	    countdowns: Countdown[];
+	    updateChannel: string;
+	    proxyUrl: string;
+	    proxyPacUrl: string;
+	    noProxy: string;
+	    caCertFiles: string[];
+	    adminPinHash: string;
+	
 	    static createFrom(source: any = {}) {
 	        return new Settings(source);
 	    }
-
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.schemaVersion = source["schemaVersion"];
+	        this.schoolName = source["schoolName"];
+	        this.schoolCode = source["schoolCode"];
+	        this.officeCode = source["officeCode"];
+	        this.grade = source["grade"];
+	        this.classNum = source["classNum"];
+	        this.latitude = source["latitude"];
+	        this.longitude = source["longitude"];
+	        this.spreadsheetUrl = source["spreadsheetUrl"];
+	        this.icsSources = source["icsSources"];
+	        this.eventLimit = source["eventLimit"];
+	        this.eventWindowDays = source["eventWindowDays"];
+	        this.useCustomApiKey = source["useCustomApiKey"];
+	        this.customApiKey = source["customApiKey"];
+	        this.alarmEnabled = source["alarmEnabled"];
+	        this.alarmSound = source["alarmSound"];
+	        this.bellSounds = this.convertValues(source["bellSounds"], BellSounds);
+	        this.customSounds = this.convertValues(source["customSounds"], CustomSound);
+	        this.bells = this.convertValues(source["bells"], BellConfig);
+	        this.muteBellsDate = source["muteBellsDate"];
+	        this.backgroundId = source["backgroundId"];
+	        this.backgroundRotation = this.convertValues(source["backgroundRotation"], BackgroundRotation);
+	        this.customBackgrounds = this.convertValues(source["customBackgrounds"], CustomBackground);
+	        this.countdowns = this.convertValues(source["countdowns"], Countdown);
+	        this.updateChannel = source["updateChannel"];
+	        this.proxyUrl = source["proxyUrl"];
+	        this.proxyPacUrl = source["proxyPacUrl"];
+	        this.noProxy = source["noProxy"];
+	        this.caCertFiles = source["caCertFiles"];
+	        this.adminPinHash = source["adminPinHash"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SettingsStatus {
+	    recovered: boolean;
+	    reset: boolean;
+	    backupFile?: string;
+	    corruptFile?: string;
+	    // Go type: time
+	    time: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new SettingsStatus(source);
+	    }
+	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.recovered = source["recovered"];
+	        this.reset = source["reset"];
+	        this.backupFile = source["backupFile"];
+	        this.corruptFile = source["corruptFile"];
+	        this.time = this.convertValues(source["time"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SettingsView {
+	    schemaVersion: number;
+	    schoolName: string;
+	    schoolCode: string;
+	    officeCode: string;
+	    grade: number;
+	    classNum: number;
+	    latitude: number;
+	    longitude: number;
+	    spreadsheetUrl: string;
+	    icsSources: string[];
+	    eventLimit: number;
+	    eventWindowDays: number;
+	    useCustomApiKey: boolean;
+	    customApiKey: string;
+	    alarmEnabled: boolean;
+	    alarmSound: string;
+	    bellSounds: BellSounds;
+	    customSounds: CustomSound[];
+	    bells: BellConfig;
+	    muteBellsDate: string;
+	    backgroundId: string;
+	    backgroundRotation: BackgroundRotation;
+	    customBackgrounds: CustomBackground[];
+	    countdowns: Countdown[];
+	    updateChannel: string;
+	    proxyUrl: string;
+	    proxyPacUrl: string;
+	    noProxy: string;
+	    caCertFiles: string[];
+	    adminPinHash: string;
+	    lockedFields: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SettingsView(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.schemaVersion = source["schemaVersion"];
 	        this.schoolName = source["schoolName"];
 	        this.schoolCode = source["schoolCode"];
 	        this.officeCode = source["officeCode"];
@@ -349,14 +978,28 @@ export namespace main {
 	        this.latitude = source["latitude"];
 	        this.longitude = source["longitude"];
 	        this.spreadsheetUrl = source["spreadsheetUrl"];
+	        this.icsSources = source["icsSources"];
+	        this.eventLimit = source["eventLimit"];
+	        this.eventWindowDays = source["eventWindowDays"];
 	        this.useCustomApiKey = source["useCustomApiKey"];
 	        this.customApiKey = source["customApiKey"];
 	        this.alarmEnabled = source["alarmEnabled"];
 	        this.alarmSound = source["alarmSound"];
-	        this.customAlarmData = source["customAlarmData"];
-	        this.customAlarmName = source["customAlarmName"];
+	        this.bellSounds = this.convertValues(source["bellSounds"], BellSounds);
+	        this.customSounds = this.convertValues(source["customSounds"], CustomSound);
+	        this.bells = this.convertValues(source["bells"], BellConfig);
+	        this.muteBellsDate = source["muteBellsDate"];
 	        this.backgroundId = source["backgroundId"];
+	        this.backgroundRotation = this.convertValues(source["backgroundRotation"], BackgroundRotation);
 	        this.customBackgrounds = this.convertValues(source["customBackgrounds"], CustomBackground);
+	        this.countdowns = this.convertValues(source["countdowns"], Countdown);
+	        this.updateChannel = source["updateChannel"];
+	        this.proxyUrl = source["proxyUrl"];
+	        this.proxyPacUrl = source["proxyPacUrl"];
+	        this.noProxy = source["noProxy"];
+	        this.caCertFiles = source["caCertFiles"];
+	        this.adminPinHash = source["adminPinHash"];
+	        this.lockedFields = source["lockedFields"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -380,6 +1023,7 @@ export namespace main {
 	
 	
 	
+	
 	export class UpdateCheckResult {
 	    updateAvailable: boolean;
 	    currentVersion: string;
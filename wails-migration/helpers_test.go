@@ -2,6 +2,8 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"regexp"
 	"testing"
 	"time"
@@ -10,6 +12,18 @@ import (
 // yyyymmdd is the canonical regular expression for YYYYMMDD date strings.
 var yyyymmdd = regexp.MustCompile(`^\d{8}$`)
 
+// writeMediaFile creates dir if needed and writes name in it, for tests of
+// the backgrounds and sounds folders.
+func writeMediaFile(t *testing.T, dir, name string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
 // parseYYYYMMDD parses a YYYYMMDD string into a time.Time (date only).
 // It fails the test immediately if the string is malformed.
 func parseYYYYMMDD(t *testing.T, s string) time.Time {
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// holidayCacheTTL is how long fetched NEIS holiday data is reused before the
+// school schedule is requested again.
+const holidayCacheTTL = 6 * time.Hour
+
+// holidayRetryAfterError is how long a failed holiday fetch is remembered,
+// so a NEIS outage costs one request every few minutes instead of one per
+// lookup. It is also how often runHolidays checks whether data went stale.
+const holidayRetryAfterError = 5 * time.Minute
+
+// schoolCalendar caches the NEIS 휴업일/공휴일 dates of the configured school,
+// so countdowns and bell muting can tell school days from days off without a
+// NEIS call each time.
+type schoolCalendar struct {
+	fetchMu sync.Mutex // one NEIS request at a time; mu is never held across it
+
+	mu           sync.Mutex
+	school       string // office + "/" + school code the cache belongs to
+	from, to     string // YYYYMMDD range covered by holidays
+	holidays     map[string]bool
+	fetched      time.Time
+	failedSchool string    // school whose last fetch failed
+	failed       time.Time // when that fetch failed
+}
+
+// covers reports whether the cache holds fresh data for [from, to].
+// The caller must hold c.mu.
+func (c *schoolCalendar) covers(school, from, to string, now time.Time) bool {
+	return c.holidays != nil && c.school == school &&
+		c.from <= from && c.to >= to && now.Sub(c.fetched) < holidayCacheTTL
+}
+
+// known returns the last data fetched for school, or an empty set.
+// The caller must hold c.mu.
+func (c *schoolCalendar) known(school string) map[string]bool {
+	if c.holidays != nil && c.school == school {
+		return c.holidays
+	}
+	return map[string]bool{}
+}
+
+// lookup returns the holidays to use without a NEIS request: fresh data for
+// [from, to], or the last known data while a recent failure is remembered.
+// It reports false when a request should be made.
+func (c *schoolCalendar) lookup(school, from, to string, now time.Time) (map[string]bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.covers(school, from, to, now) {
+		return c.holidays, true
+	}
+	if c.failedSchool == school && now.Sub(c.failed) < holidayRetryAfterError {
+		return c.known(school), true
+	}
+	return nil, false
+}
+
+// cached returns whatever is known about [from, to] for school, stale or
+// not, and nil if nothing is. It never waits for NEIS.
+func (c *schoolCalendar) cached(school, from, to string) map[string]bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.holidays != nil && c.school == school && c.from <= from && c.to >= to {
+		return c.holidays
+	}
+	return nil
+}
+
+// schoolHolidays returns the set of NEIS holiday dates (YYYYMMDD) between
+// from and to, fetching them if the cache is stale. On a fetch error the
+// last known data is returned, which may be empty; weekends are not
+// included. The bell loop must not call this: it uses calendar.cached.
+func (a *App) schoolHolidays(from, to string) map[string]bool {
+	s := loadSettings()
+	keys := a.neisKeys(s)
+	if len(keys) == 0 || s.SchoolCode == "" || s.OfficeCode == "" {
+		return map[string]bool{}
+	}
+	school := s.OfficeCode + "/" + s.SchoolCode
+
+	c := &a.calendar
+	if holidays, ok := c.lookup(school, from, to, time.Now()); ok {
+		return holidays
+	}
+
+	c.fetchMu.Lock()
+	defer c.fetchMu.Unlock()
+	// Another caller may have fetched while we waited.
+	now := time.Now()
+	if holidays, ok := c.lookup(school, from, to, now); ok {
+		return holidays
+	}
+
+	// Fetch at least the range the dashboard shows so that nearby lookups hit the cache.
+	fetchTo := endOfMonthPlus2()
+	if to > fetchTo {
+		fetchTo = to
+	}
+	var events []ScheduleEvent
+	_, err := a.keyRing.call(keys, now, func(apiKey string) (err error) {
+		events, err = fetchSchoolEvents(a.ctx, apiKey, s.OfficeCode, s.SchoolCode, from, fetchTo)
+		return err
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.failedSchool, c.failed = school, time.Now()
+		return c.known(school)
+	}
+	c.school, c.from, c.to, c.fetched = school, from, fetchTo, now
+	c.holidays = holidayDates(events)
+	c.failedSchool = ""
+	return c.holidays
+}
+
+// runHolidays keeps today's holiday data fresh in the background, so the
+// bell loop only ever reads the cache. It fetches at startup, when the data
+// goes stale or a failure expires, and after the settings changed.
+func (a *App) runHolidays() {
+	for {
+		today := todayStr()
+		a.schoolHolidays(today, today)
+
+		timer := time.NewTimer(holidayRetryAfterError)
+		select {
+		case <-a.quit:
+			timer.Stop()
+			return
+		case <-a.holidaysChanged:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// notifyHolidaysChanged wakes the holiday loop, e.g. after the school changed.
+func (a *App) notifyHolidaysChanged() {
+	select {
+	case a.holidaysChanged <- struct{}{}:
+	default:
+	}
+}
+
+func holidayDates(events []ScheduleEvent) map[string]bool {
+	holidays := make(map[string]bool)
+	for _, e := range events {
+		if e.Holiday {
+			holidays[e.Date] = true
+		}
+	}
+	return holidays
+}
+
+// isSchoolDay reports whether d is a weekday that is not a NEIS holiday.
+func isSchoolDay(d time.Time, holidays map[string]bool) bool {
+	if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+		return false
+	}
+	return !holidays[d.Format("20060102")]
+}
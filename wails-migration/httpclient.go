@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Outbound requests go through httpGet, which gives every attempt a
+// deadline and retries transient failures (timeouts, dropped connections,
+// 5xx and 429 responses) with jittered exponential backoff. The caller's
+// context bounds the whole call, so cancelling it stops the wait at once.
+const (
+	requestTimeout  = 15 * time.Second // per attempt, including the body
+	requestAttempts = 3
+	maxResponseSize = 16 << 20
+)
+
+// retryBaseDelay is the first backoff, doubled for each further retry. It is
+// a variable so tests need not wait.
+var retryBaseDelay = 500 * time.Millisecond
+
+// httpClient is shared by every outbound call. Timeouts come from the
+// request contexts; proxy and trusted roots from applyNetworkSettings.
+var httpClient = &http.Client{Transport: sharedTransport}
+
+// httpGet GETs u with the given extra headers, which may be nil. The body is
+// read before returning, so the response stays usable after the attempt's
+// deadline. A response that is still 5xx after the last attempt is returned
+// as is, for the caller's status check.
+func httpGet(ctx context.Context, u string, header http.Header) (*http.Response, error) {
+	return httpGetEach(ctx, u, header, nil)
+}
+
+// httpGetEach is httpGet with a hook run before every attempt, so calls
+// against a daily quota can be counted one by one.
+func httpGetEach(ctx context.Context, u string, header http.Header, beforeAttempt func()) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if beforeAttempt != nil {
+			beforeAttempt()
+		}
+		resp, err := getOnce(ctx, u, header)
+		retry := err == nil && retryableStatus(resp.StatusCode) || err != nil && isTransient(err)
+		if !retry || attempt == requestAttempts-1 || ctx.Err() != nil {
+			return resp, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff(attempt)):
+		}
+	}
+}
+
+func getOnce(ctx context.Context, u string, header http.Header) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+func retryableStatus(code int) bool {
+	return code >= 500 || code == http.StatusTooManyRequests
+}
+
+// isTransient reports whether a failed attempt is worth repeating: a
+// timeout or a connection that broke mid-request. Refused connections and
+// DNS failures are not; they rarely clear within seconds.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op != "dial"
+}
+
+// backoff returns the wait before retry attempt+1: a random duration
+// between half and all of retryBaseDelay<<attempt.
+func backoff(attempt int) time.Duration {
+	d := retryBaseDelay << attempt
+	if d < 2 {
+		return d
+	}
+	return d/2 + rand.N(d/2)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func fastRetries(t *testing.T) {
+	t.Helper()
+	old := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = old })
+}
+
+func TestHTTPGet_RetriesServerErrors(t *testing.T) {
+	fastRetries(t)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < requestAttempts {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	resp, err := httpGet(context.Background(), srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" || calls.Load() != requestAttempts {
+		t.Errorf("got %d %q after %d calls", resp.StatusCode, body, calls.Load())
+	}
+}
+
+func TestHTTPGet_GivesUpAfterLastAttempt(t *testing.T) {
+	fastRetries(t)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	resp, err := httpGet(context.Background(), srv.URL, nil)
+	if err != nil || resp.StatusCode != http.StatusBadGateway || calls.Load() != requestAttempts {
+		t.Errorf("got %v, %v after %d calls; want the last 502", resp, err, calls.Load())
+	}
+}
+
+func TestHTTPGet_DoesNotRetryClientErrors(t *testing.T) {
+	fastRetries(t)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if _, err := httpGet(context.Background(), srv.URL, nil); err != nil || calls.Load() != 1 {
+		t.Errorf("err = %v after %d calls; want one call", err, calls.Load())
+	}
+}
+
+func TestHTTPGet_RetriesDroppedConnections(t *testing.T) {
+	fastRetries(t)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer srv.Close()
+
+	resp, err := httpGet(context.Background(), srv.URL, nil)
+	if err != nil || resp.StatusCode != http.StatusOK || calls.Load() != 2 {
+		t.Errorf("got %v, %v after %d calls; want success on the retry", resp, err, calls.Load())
+	}
+}
+
+func TestHTTPGet_SendsHeaders(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer srv.Close()
+
+	header := http.Header{}
+	header.Set("User-Agent", "Wall-E-Test")
+	if _, err := httpGet(context.Background(), srv.URL, header); err != nil || got != "Wall-E-Test" {
+		t.Errorf("User-Agent = %q, err %v", got, err)
+	}
+}
+
+func TestHTTPGet_Cancel(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := httpGet(ctx, srv.URL, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("cancel took %v", d)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 4; attempt++ {
+		full := retryBaseDelay << attempt
+		for i := 0; i < 20; i++ {
+			if d := backoff(attempt); d < full/2 || d >= full {
+				t.Fatalf("backoff(%d) = %v, want in [%v, %v)", attempt, d, full/2, full)
+			}
+		}
+	}
+}
+
+func TestCancelDashboardFetch(t *testing.T) {
+	a := &App{ctx: context.Background()}
+	first := a.dashboardContext()
+	if a.dashboardContext() != first {
+		t.Fatal("concurrent fetches should share a context")
+	}
+	a.CancelDashboardFetch()
+	if first.Err() == nil {
+		t.Error("fetch not cancelled")
+	}
+	if next := a.dashboardContext(); next.Err() != nil {
+		t.Error("the next fetch should get a fresh context")
+	}
+	a.CancelDashboardFetch()
+	a.CancelDashboardFetch() // nothing in flight
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ===== iCalendar (.ics) export =====
+
+const icsTimezone = "Asia/Seoul"
+
+var timetableWeekdays = map[string]time.Weekday{
+	"일": time.Sunday, "월": time.Monday, "화": time.Tuesday, "수": time.Wednesday,
+	"목": time.Thursday, "금": time.Friday, "토": time.Saturday,
+}
+
+// eventUID keeps the UID an ICS or personal event already has. For NEIS and
+// sheet events, which have none, it derives one from the date and name so
+// that re-exporting the same calendar updates existing entries instead of
+// duplicating them. The name is normalized the same way mergeEvents compares
+// names, so a tag or spacing variant keeps its UID.
+func eventUID(e ScheduleEvent) string {
+	if e.UID != "" {
+		return e.UID
+	}
+	sum := sha1.Sum([]byte(e.Date + "|" + normalizeEventName(e.Name)))
+	return "event-" + hex.EncodeToString(sum[:10]) + "@wall-e"
+}
+
+func escapeICSText(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+// foldICSLine splits a content line into 75-octet chunks without breaking
+// multi-byte (Korean) characters, as required by RFC 5545.
+func foldICSLine(line string) string {
+	if len(line) <= 75 {
+		return line + "\r\n"
+	}
+	var b strings.Builder
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // the leading space counts towards the next line
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
+
+// buildICS renders the merged event list (and optionally the weekly
+// timetable as recurring events) as an iCalendar document.
+func buildICS(calName string, events []ScheduleEvent, tt *TimetableData, now time.Time) string {
+	var b strings.Builder
+	write := func(line string) { b.WriteString(foldICSLine(line)) }
+	stamp := now.UTC().Format("20060102T150405Z")
+
+	write("BEGIN:VCALENDAR")
+	write("VERSION:2.0")
+	write("PRODID:-//Wall-E//School Dashboard//KO")
+	write("CALSCALE:GREGORIAN")
+	write("METHOD:PUBLISH")
+	if calName != "" {
+		write("X-WR-CALNAME:" + escapeICSText(calName))
+	}
+	write("X-WR-TIMEZONE:" + icsTimezone)
+
+	if tt != nil && len(tt.Periods) > 0 {
+		// Korea has no daylight saving time, so a single STANDARD block is enough.
+		write("BEGIN:VTIMEZONE")
+		write("TZID:" + icsTimezone)
+		write("BEGIN:STANDARD")
+		write("DTSTART:19700101T000000")
+		write("TZOFFSETFROM:+0900")
+		write("TZOFFSETTO:+0900")
+		write("TZNAME:KST")
+		write("END:STANDARD")
+		write("END:VTIMEZONE")
+	}
+
+	for _, e := range events {
+		start, err := time.ParseInLocation("20060102", e.Date, time.Local)
+		if err != nil {
+			continue
+		}
+		end := start.AddDate(0, 0, 1)
+		if e.EndDate != "" {
+			if last, err := time.ParseInLocation("20060102", e.EndDate, time.Local); err == nil && last.After(start) {
+				end = last.AddDate(0, 0, 1)
+			}
+		}
+
+		write("BEGIN:VEVENT")
+		write("UID:" + eventUID(e))
+		write("DTSTAMP:" + stamp)
+		write("DTSTART;VALUE=DATE:" + e.Date)
+		write("DTEND;VALUE=DATE:" + end.Format("20060102"))
+		write("SUMMARY:" + escapeICSText(e.Name))
+		if e.Detail != "" {
+			write("DESCRIPTION:" + escapeICSText(e.Detail))
+		}
+		write("TRANSP:TRANSPARENT")
+		write("END:VEVENT")
+	}
+
+	if tt != nil {
+		writeTimetableEvents(write, tt, stamp, now)
+	}
+
+	write("END:VCALENDAR")
+	return b.String()
+}
+
+// writeTimetableEvents emits one weekly-recurring VEVENT per (weekday, period)
+// cell, anchored on the current week.
+func writeTimetableEvents(write func(string), tt *TimetableData, stamp string, now time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	offset := (int(today.Weekday()) + 6) % 7 // days since Monday
+	monday := today.AddDate(0, 0, -offset)
+
+	for d, header := range tt.Headers {
+		first, _ := utf8.DecodeRuneInString(strings.TrimSpace(header))
+		wd, ok := timetableWeekdays[string(first)]
+		if !ok {
+			continue
+		}
+		day := monday.AddDate(0, 0, (int(wd)+6)%7)
+		byDay := strings.ToUpper(wd.String()[:2])
+
+		for i, p := range tt.Periods {
+			if i >= len(tt.Subjects) || d >= len(tt.Subjects[i]) {
+				continue
+			}
+			subject := strings.TrimSpace(tt.Subjects[i][d])
+			if subject == "" {
+				continue
+			}
+			start := strings.ReplaceAll(p.Start, ":", "") + "00"
+			end := strings.ReplaceAll(p.End, ":", "") + "00"
+
+			write("BEGIN:VEVENT")
+			write(fmt.Sprintf("UID:timetable-%s-%d@wall-e", strings.ToLower(byDay), p.Period))
+			write("DTSTAMP:" + stamp)
+			write(fmt.Sprintf("DTSTART;TZID=%s:%sT%s", icsTimezone, day.Format("20060102"), start))
+			write(fmt.Sprintf("DTEND;TZID=%s:%sT%s", icsTimezone, day.Format("20060102"), end))
+			write("RRULE:FREQ=WEEKLY;BYDAY=" + byDay)
+			write(fmt.Sprintf("SUMMARY:%d교시 %s", p.Period, escapeICSText(subject)))
+			write("END:VEVENT")
+		}
+	}
+}
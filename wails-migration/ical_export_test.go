@@ -0,0 +1,126 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// --- eventUID ---
+
+func TestEventUID_StableAcrossDetailAndSource(t *testing.T) {
+	a := ScheduleEvent{Date: "20260302", Name: "개학식", Detail: "강당", Source: "neis"}
+	b := ScheduleEvent{Date: "20260302", Name: "개학식 ", Detail: "", Source: "sheet"}
+	if eventUID(a) != eventUID(b) {
+		t.Errorf("expected same UID, got %q and %q", eventUID(a), eventUID(b))
+	}
+}
+
+func TestEventUID_StableAcrossTagAndSpacingVariants(t *testing.T) {
+	a := ScheduleEvent{Date: "20261015", Name: "현장체험학습", Source: "neis"}
+	b := ScheduleEvent{Date: "20261015", Name: "[학사] 현장 체험학습", Source: "ics"}
+	if eventUID(a) != eventUID(b) {
+		t.Errorf("expected same UID, got %q and %q", eventUID(a), eventUID(b))
+	}
+}
+
+func TestEventUID_DiffersByDate(t *testing.T) {
+	a := ScheduleEvent{Date: "20260302", Name: "회의"}
+	b := ScheduleEvent{Date: "20260309", Name: "회의"}
+	if eventUID(a) == eventUID(b) {
+		t.Error("expected different UIDs for different dates")
+	}
+}
+
+func TestEventUID_KeepsSourceUID(t *testing.T) {
+	e := ScheduleEvent{Date: "20260302", Name: "개학식", Source: "ics", UID: "abc123@google.com"}
+	if got := eventUID(e); got != "abc123@google.com" {
+		t.Errorf("got %q, want the source UID", got)
+	}
+}
+
+func TestBuildICS_ReexportedICSEventKeepsUID(t *testing.T) {
+	src := wrapICS(`UID:abc123@google.com
+DTSTART;VALUE=DATE:20260302
+SUMMARY:개학식`)
+	events := icsToEvents(parseICS(src), icsDate(t, "20260301"), icsDate(t, "20260331"))
+
+	text := buildICS("테스트", events, nil, time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local))
+
+	if !strings.Contains(text, "UID:abc123@google.com\r\n") {
+		t.Errorf("expected the source UID in the export, got:\n%s", text)
+	}
+}
+
+// --- foldICSLine ---
+
+func TestFoldICSLine_ShortLineUnchanged(t *testing.T) {
+	if got := foldICSLine("SUMMARY:개학식"); got != "SUMMARY:개학식\r\n" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestFoldICSLine_LongKoreanLineKeepsRunesIntact(t *testing.T) {
+	line := "DESCRIPTION:" + strings.Repeat("가나다라마바사", 10)
+	folded := foldICSLine(line)
+
+	for _, part := range strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n") {
+		if len(part) > 75 {
+			t.Errorf("line exceeds 75 octets: %d", len(part))
+		}
+	}
+	unfolded := strings.Join(unfoldICSLines(folded), "")
+	if unfolded != line {
+		t.Errorf("unfold(fold(x)) != x\n got: %q\nwant: %q", unfolded, line)
+	}
+}
+
+// --- buildICS ---
+
+func TestBuildICS_RoundTripThroughParser(t *testing.T) {
+	events := []ScheduleEvent{
+		{Date: "20260302", Name: "개학식", Detail: "강당, 9시"},
+		{Date: "20260720", EndDate: "20260723", Name: "수련회"},
+	}
+	text := buildICS("테스트초 학사일정", events, nil, time.Date(2026, 3, 1, 9, 0, 0, 0, time.Local))
+
+	got := icsToEvents(parseICS(text), icsDate(t, "20260301"), icsDate(t, "20261231"))
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(got))
+	}
+	if got[0].Name != "개학식" || got[0].Detail != "강당, 9시" {
+		t.Errorf("event 0: got %+v", got[0])
+	}
+	if got[1].Date != "20260720" || got[1].EndDate != "20260723" {
+		t.Errorf("event 1: got %s~%s", got[1].Date, got[1].EndDate)
+	}
+	if !strings.Contains(text, "X-WR-CALNAME:테스트초 학사일정") {
+		t.Error("expected calendar name header")
+	}
+}
+
+func TestBuildICS_TimetableAsWeeklyRecurrence(t *testing.T) {
+	tt := &TimetableData{
+		Headers:  []string{"월", "화요일"},
+		Periods:  []PeriodTime{{Period: 1, Start: "09:00", End: "09:40"}},
+		Subjects: [][]string{{"국어", ""}},
+	}
+	// Wednesday 2026-03-04 -> the week's Monday is 2026-03-02.
+	text := buildICS("", nil, tt, time.Date(2026, 3, 4, 10, 0, 0, 0, time.Local))
+
+	for _, want := range []string{
+		"UID:timetable-mo-1@wall-e",
+		"DTSTART;TZID=Asia/Seoul:20260302T090000",
+		"DTEND;TZID=Asia/Seoul:20260302T094000",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO",
+		"SUMMARY:1교시 국어",
+		"BEGIN:VTIMEZONE",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected output to contain %q", want)
+		}
+	}
+	if strings.Contains(text, "timetable-tu-1") {
+		t.Error("empty timetable cells must not be exported")
+	}
+}
@@ -0,0 +1,22 @@
+//go:build windows
+
+package main
+
+import "golang.org/x/sys/windows/registry"
+
+const machineGUIDRegKey = `SOFTWARE\Microsoft\Cryptography`
+
+// machineID returns the Windows MachineGuid, created at install time and
+// unique per Windows installation. Empty if it cannot be read.
+func machineID() string {
+	k, err := registry.OpenKey(registry.LOCAL_MACHINE, machineGUIDRegKey, registry.QUERY_VALUE|registry.WOW64_64KEY)
+	if err != nil {
+		return ""
+	}
+	defer k.Close()
+	guid, _, err := k.GetStringValue("MachineGuid")
+	if err != nil {
+		return ""
+	}
+	return guid
+}
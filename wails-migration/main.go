@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/wailsapp/wails/v2"
@@ -60,10 +61,22 @@ func readEnvKey(path, key string) string {
 }
 
 func main() {
+	resetPIN := slices.Contains(os.Args[1:], resetPINFlag)
 	if !ensureSingleInstance() {
+		if resetPIN {
+			// The running instance would keep the old PIN in memory and save it back.
+			log.Printf("reset PIN: Wall-E is already running; end it in Task Manager and start again with %s", resetPINFlag)
+		}
 		return
 	}
 
+	// Recovery for a forgotten admin PIN; the app then starts as usual.
+	if resetPIN {
+		if err := resetAdminPIN(); err != nil {
+			log.Printf("reset PIN: %v", err)
+		}
+	}
+
 	apiKey := resolveNeisAPIKey()
 	app := NewApp(apiKey)
 
@@ -74,10 +87,12 @@ func main() {
 		MinWidth:  800,
 		MinHeight: 600,
 		AssetServer: &assetserver.Options{
-			Assets: assets,
+			Assets:  assets,
+			Handler: customMediaHandler{},
 		},
 		BackgroundColour: &options.RGBA{R: 232, G: 236, B: 244, A: 0},
 		OnStartup:        app.startup,
+		OnBeforeClose:    app.beforeClose,
 		OnShutdown:       app.shutdown,
 		Bind: []interface{}{
 			app,
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Imported backgrounds and sounds are served by the asset server at local
+// URLs instead of being inlined as data URLs, so the webview streams and
+// caches them like any other asset.
+const (
+	customBackgroundPrefix = "/custom/backgrounds/"
+	customThumbPrefix      = "/custom/thumbs/"
+	customSoundPrefix      = "/custom/sounds/"
+)
+
+var imageMimeTypes = map[string]string{
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".png":  "image/png",
+	".webp": "image/webp",
+	".bmp":  "image/bmp",
+}
+
+func imageMimeType(fileName string) string {
+	if t := imageMimeTypes[strings.ToLower(filepath.Ext(fileName))]; t != "" {
+		return t
+	}
+	return "image/jpeg"
+}
+
+func backgroundsDir() string {
+	return filepath.Join(settingsDir, "backgrounds")
+}
+
+func customBackgroundURL(id string) string {
+	return customBackgroundPrefix + id
+}
+
+func customThumbURL(id string) string {
+	return customThumbPrefix + id
+}
+
+func customSoundURL(id string) string {
+	return customSoundPrefix + id
+}
+
+// customMediaHandler is the asset server fallback for /custom/... paths.
+// Files are looked up by ID in settings, so only imported files are reachable.
+type customMediaHandler struct{}
+
+func (customMediaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path, contentType, ok := resolveCustomMedia(loadSettings(), r.URL.Path)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	// Imported files get a fresh ID and are never rewritten in place.
+	w.Header().Set("Cache-Control", "private, max-age=31536000, immutable")
+	http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+}
+
+// resolveCustomMedia maps a /custom/... request path to the file on disk.
+func resolveCustomMedia(s Settings, urlPath string) (string, string, bool) {
+	if id, ok := strings.CutPrefix(urlPath, customBackgroundPrefix); ok {
+		for _, bg := range s.CustomBackgrounds {
+			if bg.Id == id {
+				return filepath.Join(backgroundsDir(), filepath.Base(bg.FileName)), imageMimeType(bg.FileName), true
+			}
+		}
+		return "", "", false
+	}
+	if id, ok := strings.CutPrefix(urlPath, customThumbPrefix); ok {
+		for _, bg := range s.CustomBackgrounds {
+			if bg.Id == id && bg.ThumbFileName != "" {
+				return filepath.Join(backgroundThumbsDir(), filepath.Base(bg.ThumbFileName)), "image/jpeg", true
+			}
+		}
+		return "", "", false
+	}
+	if id, ok := strings.CutPrefix(urlPath, customSoundPrefix); ok {
+		if sound, found := findCustomSound(s, id); found {
+			return filepath.Join(soundsDir(), filepath.Base(sound.FileName)), audioMimeType(sound.FileName), true
+		}
+	}
+	return "", "", false
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupCustomMedia stores one background and one sound in a temp settings dir.
+func setupCustomMedia(t *testing.T) func() {
+	t.Helper()
+	_, cleanup := overrideSettingsPath(t)
+
+	s := defaultSettings
+	s.CustomBackgrounds = []CustomBackground{{Id: "bg1", Name: "교실.png", FileName: "bg1.png"}}
+	s.CustomSounds = []CustomSound{{Id: "snd1", Name: "종.mp3", FileName: "snd1.mp3"}}
+	if err := saveSettings(s); err != nil {
+		t.Fatalf("saveSettings: %v", err)
+	}
+	for dir, file := range map[string]string{backgroundsDir(): "bg1.png", soundsDir(): "snd1.mp3"} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("MkdirAll: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), []byte("0123456789"), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+	}
+	return cleanup
+}
+
+func serveCustomMedia(method, path string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	rec := httptest.NewRecorder()
+	customMediaHandler{}.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestCustomMediaHandler_ServesBackground(t *testing.T) {
+	defer setupCustomMedia(t)()
+
+	rec := serveCustomMedia(http.MethodGet, customBackgroundURL("bg1"), nil)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status: got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Content-Type: got %q", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); cc == "" {
+		t.Error("expected a Cache-Control header")
+	}
+	if rec.Body.String() != "0123456789" {
+		t.Errorf("body: got %q", rec.Body.String())
+	}
+}
+
+func TestCustomMediaHandler_SoundSupportsRange(t *testing.T) {
+	defer setupCustomMedia(t)()
+
+	rec := serveCustomMedia(http.MethodGet, customSoundURL("snd1"), http.Header{"Range": {"bytes=2-4"}})
+
+	if rec.Code != http.StatusPartialContent || rec.Body.String() != "234" {
+		t.Errorf("got %d %q", rec.Code, rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "audio/mpeg" {
+		t.Errorf("Content-Type: got %q", ct)
+	}
+}
+
+func TestCustomMediaHandler_UnknownIDNotFound(t *testing.T) {
+	defer setupCustomMedia(t)()
+
+	for _, path := range []string{
+		customBackgroundURL("nope"),
+		customSoundURL("../settings.json"),
+		"/custom/other/bg1",
+	} {
+		if rec := serveCustomMedia(http.MethodGet, path, nil); rec.Code != http.StatusNotFound {
+			t.Errorf("%s: got %d, want 404", path, rec.Code)
+		}
+	}
+}
+
+func TestCustomMediaHandler_RejectsWrites(t *testing.T) {
+	defer setupCustomMedia(t)()
+
+	if rec := serveCustomMedia(http.MethodPost, customBackgroundURL("bg1"), nil); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("got %d, want 405", rec.Code)
+	}
+}
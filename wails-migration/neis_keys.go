@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// NEIS calls try the available keys in order: the preferred one (personal
+// when enabled, otherwise built-in), then the other. A key NEIS refuses as
+// invalid or over quota is skipped until its cooldown ends, so a revoked
+// personal key or a spent built-in key does not empty the dashboard.
+const (
+	neisKeyPersonal = "personal"
+	neisKeyBuiltin  = "builtin"
+
+	neisInvalidKeyCooldown = time.Hour // quota failures last until midnight
+)
+
+var errNoNEISKey = errors.New("사용 가능한 NEIS 인증키가 없습니다")
+
+// neisKey is one NEIS key and where it came from.
+type neisKey struct {
+	Label string // neisKeyPersonal or neisKeyBuiltin
+	Value string
+}
+
+// NEISKeyStatus is the state of a NEIS key for the diagnostics.
+type NEISKeyStatus struct {
+	Label  string    `json:"label"`
+	Status string    `json:"status"`          // "ok", "invalid" or "quota"
+	Until  time.Time `json:"until,omitempty"` // when a failed key is tried again
+}
+
+// neisAPIError is a RESULT code NEIS returned instead of data.
+type neisAPIError struct {
+	Prefix  string // names the data source, e.g. "급식 "
+	Code    string
+	Message string
+}
+
+func (e *neisAPIError) Error() string {
+	return fmt.Sprintf("%sNEIS API 오류 (%s): %s", e.Prefix, e.Code, e.Message)
+}
+
+// keyFailure returns "invalid" or "quota" when err means the key itself was
+// refused, and "" otherwise.
+func keyFailure(err error) string {
+	var apiErr *neisAPIError
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+	switch apiErr.Code {
+	case neisInvalidCode:
+		return "invalid"
+	case neisQuotaCode:
+		return "quota"
+	}
+	return ""
+}
+
+// neisKeyRing remembers which keys failed and until when. It lives in
+// memory only; a restart tries every key again.
+type neisKeyRing struct {
+	mu     sync.Mutex
+	failed map[string]NEISKeyStatus // by keyFingerprint
+}
+
+func (r *neisKeyRing) status(k neisKey, now time.Time) NEISKeyStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if st, ok := r.failed[keyFingerprint(k.Value)]; ok && now.Before(st.Until) {
+		return st
+	}
+	return NEISKeyStatus{Label: k.Label, Status: "ok"}
+}
+
+func (r *neisKeyRing) markFailed(k neisKey, reason string, now time.Time) {
+	until := now.Add(neisInvalidKeyCooldown)
+	if reason == "quota" {
+		y, m, d := now.Date()
+		until = time.Date(y, m, d+1, 0, 0, 0, 0, now.Location())
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failed == nil {
+		r.failed = make(map[string]NEISKeyStatus)
+	}
+	r.failed[keyFingerprint(k.Value)] = NEISKeyStatus{Label: k.Label, Status: reason, Until: until}
+}
+
+// clear forgets a failure, e.g. after the key passed a validation.
+func (r *neisKeyRing) clear(apiKey string) {
+	r.mu.Lock()
+	delete(r.failed, keyFingerprint(apiKey))
+	r.mu.Unlock()
+}
+
+// active returns the key the next call would use: the first one not cooling
+// down, or the first one when all are.
+func (r *neisKeyRing) active(keys []neisKey, now time.Time) neisKey {
+	for _, k := range keys {
+		if r.status(k, now).Status == "ok" {
+			return k
+		}
+	}
+	if len(keys) > 0 {
+		return keys[0]
+	}
+	return neisKey{}
+}
+
+// call runs fn with each usable key in turn until NEIS does not refuse the
+// key. It returns the key that answered, or the last one tried. When every
+// key is cooling down it tries the first one anyway, like active, so one
+// transient refusal doesn't blank the dashboard for the whole cooldown; a
+// key that answers again is cleared.
+func (r *neisKeyRing) call(keys []neisKey, now time.Time, fn func(apiKey string) error) (neisKey, error) {
+	if len(keys) == 0 {
+		return neisKey{}, errNoNEISKey
+	}
+	var usable []neisKey
+	for _, k := range keys {
+		if r.status(k, now).Status == "ok" {
+			usable = append(usable, k)
+		}
+	}
+	if len(usable) == 0 {
+		usable = keys[:1]
+	}
+
+	var last neisKey
+	var err error
+	for _, k := range usable {
+		last = k
+		err = fn(k.Value)
+		reason := keyFailure(err)
+		if reason == "" {
+			if err == nil {
+				r.clear(k.Value)
+			}
+			return k, err
+		}
+		r.markFailed(k, reason, now)
+	}
+	return last, err
+}
+
+// neisKeys lists the NEIS keys to try, the preferred one first.
+func (a *App) neisKeys(s Settings) []neisKey {
+	personal := neisKey{Label: neisKeyPersonal, Value: s.CustomAPIKey}
+	builtin := neisKey{Label: neisKeyBuiltin, Value: a.neisAPIKey}
+	ordered := []neisKey{builtin, personal}
+	if s.UseCustomAPIKey {
+		ordered = []neisKey{personal, builtin}
+	}
+	var keys []neisKey
+	for _, k := range ordered {
+		if k.Value != "" && (len(keys) == 0 || keys[0].Value != k.Value) {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// --- neisKeys ---
+
+func TestNEISKeys_Order(t *testing.T) {
+	a := &App{neisAPIKey: "built"}
+	cases := map[string]struct {
+		s    Settings
+		want []string
+	}{
+		"builtin only":      {Settings{}, []string{"built"}},
+		"personal enabled":  {Settings{UseCustomAPIKey: true, CustomAPIKey: "mine"}, []string{"mine", "built"}},
+		"personal disabled": {Settings{CustomAPIKey: "mine"}, []string{"built", "mine"}},
+		"same key":          {Settings{UseCustomAPIKey: true, CustomAPIKey: "built"}, []string{"built"}},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			keys := a.neisKeys(c.s)
+			var got []string
+			for _, k := range keys {
+				got = append(got, k.Value)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(c.want) {
+				t.Errorf("keys = %v, want %v", got, c.want)
+			}
+		})
+	}
+
+	if keys := (&App{}).neisKeys(Settings{}); len(keys) != 0 {
+		t.Errorf("no keys configured: got %v", keys)
+	}
+}
+
+// --- neisKeyRing.call ---
+
+func TestKeyRingCall_FallsBackOnInvalidKey(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	used := fakeNEIS(t, map[string]string{"mine": neisInvalidBody, "built": neisSchoolBody})
+
+	var ring neisKeyRing
+	keys := []neisKey{{neisKeyPersonal, "mine"}, {neisKeyBuiltin, "built"}}
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	var schools []SchoolInfo
+	call := func() (neisKey, error) {
+		return ring.call(keys, now, func(apiKey string) (err error) {
+			schools, err = searchSchool(context.Background(), apiKey, "테스트")
+			return err
+		})
+	}
+
+	key, err := call()
+	if err != nil || key.Label != neisKeyBuiltin || len(schools) != 1 {
+		t.Fatalf("call = %v, %v, %d schools; want builtin key and 1 school", key, err, len(schools))
+	}
+	st := ring.status(keys[0], now)
+	if st.Status != "invalid" || !st.Until.Equal(now.Add(neisInvalidKeyCooldown)) {
+		t.Errorf("personal key status = %+v", st)
+	}
+
+	// The failed key is skipped while cooling down.
+	*used = nil
+	if _, err := call(); err != nil || fmt.Sprint(*used) != "[built]" {
+		t.Errorf("second call used %v, err %v; want only the builtin key", *used, err)
+	}
+
+	// And tried again afterwards.
+	now = now.Add(neisInvalidKeyCooldown)
+	*used = nil
+	call()
+	if fmt.Sprint(*used) != "[mine built]" {
+		t.Errorf("after cooldown used %v, want [mine built]", *used)
+	}
+}
+
+func TestKeyRingCall_QuotaLastsUntilMidnight(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	fakeNEIS(t, map[string]string{"built": neisQuotaBody, "mine": neisSchoolBody})
+
+	var ring neisKeyRing
+	keys := []neisKey{{neisKeyBuiltin, "built"}, {neisKeyPersonal, "mine"}}
+	now := time.Date(2026, 3, 10, 15, 30, 0, 0, time.Local)
+	key, err := ring.call(keys, now, func(apiKey string) error {
+		_, err := searchSchool(context.Background(), apiKey, "테스트")
+		return err
+	})
+	if err != nil || key.Label != neisKeyPersonal {
+		t.Fatalf("call = %v, %v; want the personal key", key, err)
+	}
+	st := ring.status(keys[0], now)
+	if want := time.Date(2026, 3, 11, 0, 0, 0, 0, time.Local); st.Status != "quota" || !st.Until.Equal(want) {
+		t.Errorf("builtin key status = %+v, want quota until %v", st, want)
+	}
+	if got := ring.active(keys, now); got.Label != neisKeyPersonal {
+		t.Errorf("active = %v, want the personal key", got)
+	}
+}
+
+func TestKeyRingCall_AllKeysFailing(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	used := fakeNEIS(t, map[string]string{"a": neisInvalidBody, "b": neisQuotaBody})
+
+	var ring neisKeyRing
+	keys := []neisKey{{neisKeyPersonal, "a"}, {neisKeyBuiltin, "b"}}
+	now := time.Now()
+	search := func(apiKey string) error {
+		_, err := searchSchool(context.Background(), apiKey, "테스트")
+		return err
+	}
+	if _, err := ring.call(keys, now, search); keyFailure(err) != "quota" {
+		t.Errorf("err = %v, want the last key's quota error", err)
+	}
+	// With both keys cooling down, the first one is still tried.
+	*used = nil
+	if key, err := ring.call(keys, now, search); keyFailure(err) != "invalid" || fmt.Sprint(*used) != "[a]" || key.Value != "a" {
+		t.Errorf("call = %v, %v, used %v; want one request with the first key", key, err, *used)
+	}
+	if _, err := ring.call(nil, now, search); err != errNoNEISKey {
+		t.Errorf("no keys: err = %v, want errNoNEISKey", err)
+	}
+}
+
+func TestKeyRingCall_AllCoolingRecoversFirstKey(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	used := fakeNEIS(t, map[string]string{"a": neisSchoolBody, "b": neisSchoolBody})
+
+	var ring neisKeyRing
+	keys := []neisKey{{neisKeyPersonal, "a"}, {neisKeyBuiltin, "b"}}
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.Local)
+	// A transient quota refusal on both keys.
+	ring.markFailed(keys[0], "quota", now)
+	ring.markFailed(keys[1], "quota", now)
+
+	key, err := ring.call(keys, now, func(apiKey string) error {
+		_, err := searchSchool(context.Background(), apiKey, "테스트")
+		return err
+	})
+	if err != nil || key.Value != "a" || fmt.Sprint(*used) != "[a]" {
+		t.Fatalf("call = %v, %v, used %v; want the first key to answer", key, err, *used)
+	}
+	if st := ring.status(keys[0], now); st.Status != "ok" {
+		t.Errorf("first key status = %+v, want ok after it answered", st)
+	}
+}
+
+func TestKeyRingCall_OtherErrorsDoNotFallBack(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	used := fakeNEIS(t, map[string]string{"a": `{"RESULT":{"CODE":"ERROR-500","MESSAGE":"서버 오류입니다."}}`})
+
+	var ring neisKeyRing
+	keys := []neisKey{{neisKeyPersonal, "a"}, {neisKeyBuiltin, "b"}}
+	_, err := ring.call(keys, time.Now(), func(apiKey string) error {
+		_, err := searchSchool(context.Background(), apiKey, "테스트")
+		return err
+	})
+	if err == nil || fmt.Sprint(*used) != "[a]" {
+		t.Errorf("err = %v, used %v; want the server error from the first key only", err, *used)
+	}
+	if st := ring.status(keys[0], time.Now()); st.Status != "ok" {
+		t.Errorf("status = %+v, want ok", st)
+	}
+}
+
+func TestKeyRingClear(t *testing.T) {
+	var ring neisKeyRing
+	k := neisKey{neisKeyPersonal, "mine"}
+	now := time.Now()
+	ring.markFailed(k, "invalid", now)
+	ring.clear("mine")
+	if st := ring.status(k, now); st.Status != "ok" {
+		t.Errorf("status after clear = %+v", st)
+	}
+}
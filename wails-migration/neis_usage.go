@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+// NEIS Open API keys have a daily call limit. Calls are counted per key and
+// day in settingsDir/neis-usage.json, so the settings screen can show usage
+// and warn before a personal key runs out.
+const (
+	neisDailyLimit  = 1000 // calls a day for a key not registered for a service
+	neisUsageWarnAt = 800
+	neisQuotaCode   = "ERROR-337" // daily traffic limit exceeded
+	neisInvalidCode = "ERROR-290" // unknown or revoked key
+)
+
+// neisBaseURL is a variable so tests can point it at a local server.
+var neisBaseURL = "https://open.neis.go.kr/hub"
+
+// APIUsage is today's NEIS usage for the key in use.
+type APIUsage struct {
+	Date      string `json:"date"`
+	Calls     int    `json:"calls"`
+	Limit     int    `json:"limit"`
+	CustomKey bool   `json:"customKey"`
+	Warning   bool   `json:"warning"`  // a personal key is close to the limit
+	Exceeded  bool   `json:"exceeded"` // NEIS refused a call for the limit today
+}
+
+// APIKeyValidation classifies a key check for the settings screen.
+type APIKeyValidation struct {
+	Status  string `json:"status"` // "valid", "invalid", "quota", "network" or "error"
+	Message string `json:"message"`
+}
+
+// neisUsageFile is stored as neis-usage.json. Keys are identified by a hash
+// so the file holds no secrets.
+type neisUsageFile struct {
+	Date     string          `json:"date"`
+	Calls    map[string]int  `json:"calls"`
+	Exceeded map[string]bool `json:"exceeded,omitempty"`
+}
+
+var neisUsage struct {
+	mu   sync.Mutex
+	path string // file the cached data was loaded from
+	data neisUsageFile
+}
+
+func neisUsagePath() string {
+	return filepath.Join(settingsDir, "neis-usage.json")
+}
+
+func keyFingerprint(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:6])
+}
+
+// updateNEISUsage runs fn on today's usage and saves the result.
+// Usage is advisory, so a file that cannot be read or written is ignored.
+func updateNEISUsage(fn func(u *neisUsageFile)) neisUsageFile {
+	neisUsage.mu.Lock()
+	defer neisUsage.mu.Unlock()
+
+	path := neisUsagePath()
+	if neisUsage.path != path {
+		neisUsage.path = path
+		neisUsage.data = neisUsageFile{}
+		if data, err := os.ReadFile(path); err == nil {
+			_ = json.Unmarshal(data, &neisUsage.data)
+		}
+	}
+	u := &neisUsage.data
+	if today := todayStr(); u.Date != today {
+		*u = neisUsageFile{Date: today}
+	}
+	if u.Calls == nil {
+		u.Calls = make(map[string]int)
+	}
+	if fn != nil {
+		fn(u)
+		if data, err := json.Marshal(u); err == nil && os.MkdirAll(settingsDir, 0755) == nil {
+			_ = writeFileAtomic(path, data, 0644)
+		}
+	}
+	return *u
+}
+
+// neisUsageFor returns today's usage of apiKey.
+func neisUsageFor(apiKey string, customKey bool) APIUsage {
+	u := updateNEISUsage(nil)
+	fp := keyFingerprint(apiKey)
+	usage := APIUsage{
+		Date:      u.Date,
+		Calls:     u.Calls[fp],
+		Limit:     neisDailyLimit,
+		CustomKey: customKey,
+		Exceeded:  u.Exceeded[fp],
+	}
+	usage.Warning = customKey && (usage.Exceeded || usage.Calls >= neisUsageWarnAt)
+	return usage
+}
+
+// neisGet performs a NEIS request, counting it against apiKey and noting a
+// quota error in the response.
+func neisGet(ctx context.Context, apiKey, u string) (*http.Response, error) {
+	fp := keyFingerprint(apiKey)
+	resp, err := httpGetEach(ctx, u, nil, func() {
+		updateNEISUsage(func(u *neisUsageFile) { u.Calls[fp]++ })
+	})
+	if err != nil {
+		return nil, redactNEISKey(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, redactNEISKey(err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	var raw struct {
+		Result *struct {
+			Code string `json:"CODE"`
+		} `json:"RESULT"`
+	}
+	if json.Unmarshal(body, &raw) == nil && raw.Result != nil && raw.Result.Code == neisQuotaCode {
+		updateNEISUsage(func(u *neisUsageFile) {
+			if u.Exceeded == nil {
+				u.Exceeded = make(map[string]bool)
+			}
+			u.Exceeded[fp] = true
+		})
+	}
+	return resp, nil
+}
+
+var neisKeyParam = regexp.MustCompile(`([?&]KEY=)[^&]*`)
+
+// redactNEISKey masks the KEY parameter in the request URL that net/http
+// errors carry, so error messages shown in the UI or logged never hold the key.
+func redactNEISKey(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = neisKeyParam.ReplaceAllString(urlErr.URL, "${1}***")
+	}
+	return err
+}
+
+// validateAPIKey checks apiKey with a one-row school search.
+func validateAPIKey(ctx context.Context, apiKey string) APIKeyValidation {
+	u := fmt.Sprintf("%s/schoolInfo?KEY=%s&pIndex=1&pSize=1&Type=json", neisBaseURL, url.QueryEscape(apiKey))
+	resp, err := neisGet(ctx, apiKey, u)
+	if err != nil {
+		return APIKeyValidation{Status: "network", Message: "네트워크 오류: " + err.Error()}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return APIKeyValidation{Status: "error", Message: fmt.Sprintf("NEIS 서버 오류: HTTP %d", resp.StatusCode)}
+	}
+
+	var raw struct {
+		Result *struct {
+			Code    string `json:"CODE"`
+			Message string `json:"MESSAGE"`
+		} `json:"RESULT"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return APIKeyValidation{Status: "error", Message: "응답 파싱 오류"}
+	}
+	if raw.Result == nil {
+		return classifyNEISResult("", "")
+	}
+	return classifyNEISResult(raw.Result.Code, raw.Result.Message)
+}
+
+// classifyNEISResult maps a NEIS RESULT code to a validation outcome. A
+// successful call carries no top-level RESULT.
+func classifyNEISResult(code, message string) APIKeyValidation {
+	switch code {
+	case "", "INFO-000", "INFO-200":
+		return APIKeyValidation{Status: "valid", Message: "인증키가 정상입니다"}
+	case neisInvalidCode:
+		return APIKeyValidation{Status: "invalid", Message: "인증키가 유효하지 않습니다"}
+	case neisQuotaCode:
+		return APIKeyValidation{Status: "quota", Message: "오늘 호출 한도를 초과했습니다. 내일 다시 시도하세요"}
+	default:
+		return APIKeyValidation{Status: "error", Message: fmt.Sprintf("NEIS API 오류 (%s): %s", code, message)}
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	neisInvalidBody = `{"RESULT":{"CODE":"ERROR-290","MESSAGE":"인증키가 유효하지 않습니다."}}`
+	neisQuotaBody   = `{"RESULT":{"CODE":"ERROR-337","MESSAGE":"일별 트래픽 제한을 넘은 호출입니다."}}`
+	neisSchoolBody  = `{"schoolInfo":[{"head":[{"list_total_count":1}]},{"row":[{"SD_SCHUL_CODE":"7010057","ATPT_OFCDC_SC_CODE":"B10","SCHUL_NM":"테스트고등학교"}]}]}`
+)
+
+// fakeNEIS points neisBaseURL at a server that answers with the body mapped
+// to the request's KEY, or to "" for any other key. It returns the keys
+// used, in order.
+func fakeNEIS(t *testing.T, bodies map[string]string) *[]string {
+	t.Helper()
+	var used []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Query().Get("KEY")
+		used = append(used, key)
+		body, ok := bodies[key]
+		if !ok {
+			body = bodies[""]
+		}
+		fmt.Fprint(w, body)
+	}))
+	old := neisBaseURL
+	neisBaseURL = srv.URL
+	t.Cleanup(func() {
+		neisBaseURL = old
+		srv.Close()
+	})
+	return &used
+}
+
+// unreachableNEIS points neisBaseURL at a port that refuses connections.
+func unreachableNEIS(t *testing.T) {
+	t.Helper()
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	old := neisBaseURL
+	neisBaseURL = srv.URL
+	t.Cleanup(func() { neisBaseURL = old })
+}
+
+// --- validateAPIKey ---
+
+func TestNetworkErrorsHideKey(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	unreachableNEIS(t)
+
+	const key = "my-secret-neis-key"
+	if v := validateAPIKey(context.Background(), key); v.Status != "network" || strings.Contains(v.Message, key) {
+		t.Errorf("validateAPIKey = %+v", v)
+	}
+	calls := map[string]func() error{
+		"searchSchool": func() error {
+			_, err := searchSchool(context.Background(), key, "서울")
+			return err
+		},
+		"fetchMeals": func() error {
+			_, err := fetchMeals(context.Background(), key, "B10", "7010057", "20260302", "20260306")
+			return err
+		},
+		"fetchSchoolEvents": func() error {
+			_, err := fetchSchoolEvents(context.Background(), key, "B10", "7010057", "20260302", "20260331")
+			return err
+		},
+	}
+	for name, call := range calls {
+		if err := call(); err == nil || strings.Contains(err.Error(), key) || !strings.Contains(err.Error(), "KEY=***") {
+			t.Errorf("%s error = %v", name, err)
+		}
+	}
+}
+
+func TestValidateAPIKey(t *testing.T) {
+	cases := map[string]struct {
+		body string
+		want string
+	}{
+		"valid":   {`{"schoolInfo":[{"head":[{"list_total_count":1},{"RESULT":{"CODE":"INFO-000","MESSAGE":"정상 처리되었습니다."}}]},{"row":[{}]}]}`, "valid"},
+		"invalid": {neisInvalidBody, "invalid"},
+		"quota":   {neisQuotaBody, "quota"},
+		"other":   {`{"RESULT":{"CODE":"ERROR-500","MESSAGE":"서버 오류입니다."}}`, "error"},
+		"garbage": {`<html>`, "error"},
+	}
+	for name, c := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, cleanup := overrideSettingsPath(t)
+			defer cleanup()
+			fakeNEIS(t, map[string]string{"": c.body})
+
+			if got := validateAPIKey(context.Background(), "key"); got.Status != c.want {
+				t.Errorf("got %+v, want status %q", got, c.want)
+			}
+		})
+	}
+}
+
+func TestValidateAPIKey_Network(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	unreachableNEIS(t)
+
+	if got := validateAPIKey(context.Background(), "key"); got.Status != "network" {
+		t.Errorf("got %+v, want network", got)
+	}
+}
+
+// --- usage ---
+
+func TestNEISUsage_CountsPerKey(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	fakeNEIS(t, map[string]string{"": `{"RESULT":{"CODE":"INFO-200","MESSAGE":"해당하는 데이터가 없습니다."}}`})
+
+	for i := 0; i < 3; i++ {
+		validateAPIKey(context.Background(), "personal")
+	}
+	validateAPIKey(context.Background(), "other")
+
+	usage := neisUsageFor("personal", true)
+	if usage.Calls != 3 || usage.Limit != neisDailyLimit || usage.Date != todayStr() {
+		t.Errorf("got %+v", usage)
+	}
+	if usage.Warning || usage.Exceeded {
+		t.Errorf("unexpected warning: %+v", usage)
+	}
+	if got := neisUsageFor("other", false).Calls; got != 1 {
+		t.Errorf("other key: got %d calls", got)
+	}
+
+	// The count survives a restart.
+	neisUsage.path = ""
+	if got := neisUsageFor("personal", true).Calls; got != 3 {
+		t.Errorf("after reload: got %d calls", got)
+	}
+}
+
+func TestNEISUsage_CountsEveryAttempt(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	fastRetries(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	old := neisBaseURL
+	neisBaseURL = srv.URL
+	defer func() { neisBaseURL = old }()
+
+	validateAPIKey(context.Background(), "personal")
+	if got := neisUsageFor("personal", true).Calls; got != requestAttempts {
+		t.Errorf("got %d calls, want one per attempt (%d)", got, requestAttempts)
+	}
+}
+
+func TestNEISUsage_WarnsForPersonalKeys(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	fp := keyFingerprint("personal")
+	updateNEISUsage(func(u *neisUsageFile) { u.Calls[fp] = neisUsageWarnAt })
+
+	if !neisUsageFor("personal", true).Warning {
+		t.Error("expected a warning for a personal key near the limit")
+	}
+	if neisUsageFor("personal", false).Warning {
+		t.Error("the shared built-in key should not warn")
+	}
+}
+
+func TestNEISUsage_QuotaErrorMarksExceeded(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	fakeNEIS(t, map[string]string{"": neisQuotaBody})
+
+	if _, err := fetchMeals(context.Background(), "personal", "B10", "7010057", "20260302", "20260306"); err == nil {
+		t.Fatal("expected the NEIS error to be returned")
+	}
+	if usage := neisUsageFor("personal", true); !usage.Exceeded || !usage.Warning {
+		t.Errorf("got %+v", usage)
+	}
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"unicode"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// School networks often route traffic through an authenticated proxy and
+// inspect TLS with a school-issued root certificate. Settings.ProxyURL,
+// NoProxy and CACertFiles configure the transport behind httpClient, so they
+// apply to every outbound call including the updater. Instead of a fixed
+// ProxyURL, ProxyPACURL names a PAC script that picks the proxy per host.
+// Without either the HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment is used.
+
+// proxyPasswordMask stands for the saved proxy password in the settings UI.
+// It needs no escaping in a URL.
+const proxyPasswordMask = "********"
+
+// networkTransport lets the transport be replaced while requests are in
+// flight.
+type networkTransport struct {
+	current atomic.Pointer[http.Transport]
+}
+
+func (t *networkTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.current.Load().RoundTrip(req)
+}
+
+var sharedTransport = func() *networkTransport {
+	t := &networkTransport{}
+	t.current.Store(http.DefaultTransport.(*http.Transport).Clone())
+	return t
+}()
+
+// applyNetworkSettings rebuilds the shared transport from s. On error the
+// current transport stays in use.
+func applyNetworkSettings(s Settings) error {
+	t, err := newTransport(s)
+	if err != nil {
+		return err
+	}
+	if old := sharedTransport.current.Swap(t); old != nil {
+		old.CloseIdleConnections()
+	}
+	return nil
+}
+
+func newTransport(s Settings) (*http.Transport, error) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+
+	switch {
+	case s.ProxyURL != "" && s.ProxyPACURL != "":
+		return nil, errors.New("프록시 서버와 PAC 스크립트 주소 중 하나만 입력하세요")
+	case s.ProxyURL != "":
+		proxyURL, err := parseProxyURL(s.ProxyURL)
+		if err != nil {
+			return nil, err
+		}
+		proxyFor := (&httpproxy.Config{
+			HTTPProxy:  proxyURL.String(),
+			HTTPSProxy: proxyURL.String(),
+			NoProxy:    s.NoProxy,
+		}).ProxyFunc()
+		t.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFor(req.URL)
+		}
+	case s.ProxyPACURL != "":
+		pacURL, err := parsePACURL(s.ProxyPACURL)
+		if err != nil {
+			return nil, err
+		}
+		t.Proxy = pacProxy(pacURL, s.NoProxy)
+	}
+
+	if len(s.CACertFiles) > 0 {
+		pool, err := certPool(s.CACertFiles)
+		if err != nil {
+			return nil, err
+		}
+		t.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+	return t, nil
+}
+
+// parseProxyURL accepts "host:port" as well as a full http(s) or socks5 URL,
+// optionally with user:password.
+func parseProxyURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		// The parse error quotes the address; keep a password out of the UI.
+		if ue, ok := err.(*url.Error); ok {
+			ue.URL = redactUserinfo(ue.URL)
+		}
+		return nil, fmt.Errorf("프록시 주소가 올바르지 않습니다: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("지원하지 않는 프록시 형식입니다: %s", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return nil, errors.New("프록시 주소에 호스트가 없습니다")
+	}
+	return u, nil
+}
+
+// pacProxyLookup runs the PAC script at pacURL for target and returns the
+// proxies it picked, "host:port" separated by semicolons, or "" for DIRECT.
+// A variable so tests can stand in for WinHTTP.
+var pacProxyLookup = winhttpPACProxy
+
+// pacProxy asks the PAC script for each request. Hosts in noProxy and
+// loopback addresses go direct without running it. The script only sees
+// scheme and host, as in browsers, so query parameters such as the NEIS
+// key never reach it.
+func pacProxy(pacURL, noProxy string) func(*http.Request) (*url.URL, error) {
+	bypass := (&httpproxy.Config{HTTPProxy: pacURL, HTTPSProxy: pacURL, NoProxy: noProxy}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		if p, err := bypass(req.URL); err != nil || p == nil {
+			return nil, err
+		}
+		target := (&url.URL{Scheme: req.URL.Scheme, Host: req.URL.Host, Path: "/"}).String()
+		list, err := pacProxyLookup(pacURL, target)
+		if err != nil {
+			return nil, fmt.Errorf("PAC 스크립트를 실행하지 못했습니다: %w", err)
+		}
+		return firstPACProxy(list)
+	}
+}
+
+// firstPACProxy returns the first proxy of a PAC result, nil for DIRECT.
+func firstPACProxy(list string) (*url.URL, error) {
+	fields := strings.FieldsFunc(list, func(r rune) bool { return r == ';' || unicode.IsSpace(r) })
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	return parseProxyURL(fields[0])
+}
+
+// parsePACURL accepts an http(s) URL; WinHTTP downloads the script itself.
+func parsePACURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", errors.New("PAC 스크립트 주소는 http:// 또는 https:// 로 시작해야 합니다")
+	}
+	return u.String(), nil
+}
+
+// certPool returns the system roots plus the certificates in files.
+func certPool(files []string) (*x509.CertPool, error) {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	for _, file := range files {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("인증서 파일을 읽을 수 없습니다: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%s에 PEM 인증서가 없습니다", file)
+		}
+	}
+	return pool, nil
+}
+
+// maskProxyURL hides the proxy password for the settings UI. The address is
+// read like parseProxyURL does, so the schemeless "user:password@host:port"
+// form is masked too.
+func maskProxyURL(raw string) string {
+	u, err := parseProxyURL(raw)
+	if err != nil {
+		return redactUserinfo(raw)
+	}
+	if u.User == nil {
+		return raw
+	}
+	if _, ok := u.User.Password(); !ok {
+		return raw
+	}
+	u.User = url.UserPassword(u.User.Username(), proxyPasswordMask)
+	return u.String()
+}
+
+// restoreProxyPassword puts the saved password back into a proxy URL that
+// came from maskProxyURL.
+func restoreProxyPassword(raw, saved string) string {
+	u, err := parseProxyURL(raw)
+	if err != nil || u.User == nil {
+		return raw
+	}
+	if pw, ok := u.User.Password(); !ok || pw != proxyPasswordMask {
+		return raw
+	}
+	s, err := parseProxyURL(saved)
+	if err != nil || s.User == nil {
+		return raw
+	}
+	savedPW, _ := s.User.Password()
+	u.User = url.UserPassword(u.User.Username(), savedPW)
+	return u.String()
+}
+
+// redactUserinfo replaces the user:password part of a proxy address that
+// could not be parsed, for text shown in the UI.
+func redactUserinfo(raw string) string {
+	at := strings.LastIndex(raw, "@")
+	if at < 0 {
+		return raw
+	}
+	start := 0
+	if i := strings.Index(raw, "://"); i >= 0 && i < at {
+		start = i + len("://")
+	}
+	return raw[:start] + "***" + raw[at:]
+}
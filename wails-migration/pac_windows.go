@@ -0,0 +1,107 @@
+//go:build windows
+
+package main
+
+import (
+	"errors"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// PAC scripts are run by WinHTTP, the engine Windows uses for its own proxy
+// auto-configuration, so a script behaves here as it does in the browser.
+// WinHTTP downloads the script and caches it for the session.
+
+const (
+	winhttpAccessTypeNoProxy    = 1
+	winhttpAccessTypeNamedProxy = 3
+	winhttpAutoProxyConfigURL   = 0x00000002
+)
+
+var (
+	modWinHTTP                = windows.NewLazySystemDLL("winhttp.dll")
+	procWinHttpOpen           = modWinHTTP.NewProc("WinHttpOpen")
+	procWinHttpGetProxyForUrl = modWinHTTP.NewProc("WinHttpGetProxyForUrl")
+	procGlobalFree            = windows.NewLazySystemDLL("kernel32.dll").NewProc("GlobalFree")
+)
+
+// WINHTTP_AUTOPROXY_OPTIONS
+type winhttpAutoProxyOptions struct {
+	flags                 uint32
+	autoDetectFlags       uint32
+	autoConfigURL         *uint16
+	reserved1             uintptr
+	reserved2             uint32
+	autoLogonIfChallenged int32
+}
+
+// WINHTTP_PROXY_INFO
+type winhttpProxyInfo struct {
+	accessType  uint32
+	proxy       *uint16
+	proxyBypass *uint16
+}
+
+// winhttpSession is opened once and shared, so the downloaded script is
+// reused across lookups.
+var winhttpSession struct {
+	once   sync.Once
+	handle uintptr
+	err    error
+}
+
+func openWinHTTPSession() (uintptr, error) {
+	s := &winhttpSession
+	s.once.Do(func() {
+		agent, _ := windows.UTF16PtrFromString("Wall-E")
+		h, _, err := procWinHttpOpen.Call(uintptr(unsafe.Pointer(agent)), winhttpAccessTypeNoProxy, 0, 0, 0)
+		if h == 0 {
+			s.err = err
+			return
+		}
+		s.handle = h
+	})
+	return s.handle, s.err
+}
+
+func winhttpPACProxy(pacURL, target string) (string, error) {
+	session, err := openWinHTTPSession()
+	if err != nil {
+		return "", err
+	}
+	script, err := windows.UTF16PtrFromString(pacURL)
+	if err != nil {
+		return "", err
+	}
+	u, err := windows.UTF16PtrFromString(target)
+	if err != nil {
+		return "", err
+	}
+	opts := winhttpAutoProxyOptions{
+		flags:                 winhttpAutoProxyConfigURL,
+		autoConfigURL:         script,
+		autoLogonIfChallenged: 1,
+	}
+	var info winhttpProxyInfo
+	ok, _, err := procWinHttpGetProxyForUrl.Call(session, uintptr(unsafe.Pointer(u)), uintptr(unsafe.Pointer(&opts)), uintptr(unsafe.Pointer(&info)))
+	if ok == 0 {
+		var errno windows.Errno
+		if errors.As(err, &errno) && errno == 0 {
+			err = errors.New("WinHttpGetProxyForUrl failed")
+		}
+		return "", err
+	}
+	defer func() {
+		for _, p := range []*uint16{info.proxy, info.proxyBypass} {
+			if p != nil {
+				procGlobalFree.Call(uintptr(unsafe.Pointer(p)))
+			}
+		}
+	}()
+	if info.accessType != winhttpAccessTypeNamedProxy || info.proxy == nil {
+		return "", nil
+	}
+	return windows.UTF16PtrToString(info.proxy), nil
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PersonalEvent is an item a teacher added directly in the app (parent
+// meetings, due dates, ...). Stored in settingsDir/events.json.
+type PersonalEvent struct {
+	Id       string `json:"id"`
+	Date     string `json:"date"`              // YYYYMMDD
+	EndDate  string `json:"endDate,omitempty"` // YYYYMMDD, inclusive
+	Name     string `json:"name"`
+	Detail   string `json:"detail,omitempty"`
+	RemindAt string `json:"remindAt,omitempty"` // local time, "2006-01-02T15:04"
+	Reminded bool   `json:"reminded,omitempty"`
+}
+
+const remindAtLayout = "2006-01-02T15:04"
+
+var (
+	personalEventsMu sync.Mutex
+	// onPersonalEventsError, when set, is called with problems reading
+	// events.json that loadPersonalEvents swallows, including a damaged file
+	// that was moved aside.
+	onPersonalEventsError func(error)
+)
+
+func personalEventsPath() string {
+	return filepath.Join(settingsDir, "events.json")
+}
+
+// loadPersonalEvents returns the stored events, or an empty list if the
+// store can't be read.
+func loadPersonalEvents() []PersonalEvent {
+	personalEventsMu.Lock()
+	defer personalEventsMu.Unlock()
+	events, err := readPersonalEvents()
+	if err != nil {
+		reportPersonalEventsError(err)
+		return []PersonalEvent{}
+	}
+	return events
+}
+
+func reportPersonalEventsError(err error) {
+	if hook := onPersonalEventsError; hook != nil {
+		go hook(err)
+	}
+}
+
+// personalEventsCorruptPath names the copy of a damaged events.json after
+// the time it was found, so an earlier copy is never replaced.
+func personalEventsCorruptPath(now time.Time) string {
+	base := personalEventsPath() + ".corrupt-" + now.Format("20060102-150405")
+	path := base
+	for n := 2; ; n++ {
+		if _, err := os.Lstat(path); errors.Is(err, os.ErrNotExist) {
+			return path
+		}
+		path = fmt.Sprintf("%s-%d", base, n)
+	}
+}
+
+// readPersonalEvents reads events.json. A file that doesn't decode is moved
+// aside to events.json.corrupt-<time> before an empty list is returned, so
+// the next save can't overwrite what the user had. The caller must hold
+// personalEventsMu.
+func readPersonalEvents() ([]PersonalEvent, error) {
+	data, err := os.ReadFile(personalEventsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return []PersonalEvent{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var events []PersonalEvent
+	if err := json.Unmarshal(data, &events); err != nil {
+		corrupt := personalEventsCorruptPath(time.Now())
+		if rerr := os.Rename(personalEventsPath(), corrupt); rerr != nil {
+			return nil, fmt.Errorf("events.json is damaged and could not be moved aside: %w", rerr)
+		}
+		reportPersonalEventsError(fmt.Errorf("events.json is damaged (%v), kept as %s", err, filepath.Base(corrupt)))
+		return []PersonalEvent{}, nil
+	}
+	if events == nil {
+		events = []PersonalEvent{}
+	}
+	return events, nil
+}
+
+func writePersonalEvents(events []PersonalEvent) error {
+	if err := os.MkdirAll(settingsDir, 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(events, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(personalEventsPath(), data, 0644)
+}
+
+// updatePersonalEvents runs fn on the stored list under the lock and saves the result.
+func updatePersonalEvents(fn func([]PersonalEvent) ([]PersonalEvent, error)) error {
+	personalEventsMu.Lock()
+	defer personalEventsMu.Unlock()
+
+	events, err := readPersonalEvents()
+	if err != nil {
+		return err
+	}
+	events, err = fn(events)
+	if err != nil {
+		return err
+	}
+	return writePersonalEvents(events)
+}
+
+// normalizePersonalEvent validates user input and converts dates to YYYYMMDD.
+func normalizePersonalEvent(e PersonalEvent) (PersonalEvent, error) {
+	e.Name = strings.TrimSpace(e.Name)
+	e.Detail = strings.TrimSpace(e.Detail)
+	if e.Name == "" {
+		return e, errors.New("일정 이름을 입력해 주세요")
+	}
+
+	e.Date = parseDateToYYYYMMDD(e.Date)
+	if e.Date == "" {
+		return e, errors.New("날짜 형식이 올바르지 않습니다")
+	}
+	if e.EndDate != "" {
+		e.EndDate = parseDateToYYYYMMDD(e.EndDate)
+		if e.EndDate == "" || e.EndDate < e.Date {
+			return e, errors.New("종료일이 올바르지 않습니다")
+		}
+		if e.EndDate == e.Date {
+			e.EndDate = ""
+		}
+	}
+
+	if e.RemindAt != "" {
+		t, err := time.ParseInLocation(remindAtLayout, strings.TrimSpace(e.RemindAt), time.Local)
+		if err != nil {
+			return e, errors.New("알림 시간 형식이 올바르지 않습니다")
+		}
+		e.RemindAt = t.Format(remindAtLayout)
+	}
+	return e, nil
+}
+
+func addPersonalEvent(e PersonalEvent) (PersonalEvent, error) {
+	e, err := normalizePersonalEvent(e)
+	if err != nil {
+		return e, err
+	}
+	e.Id = uuid.New().String()
+	e.Reminded = false
+
+	err = updatePersonalEvents(func(events []PersonalEvent) ([]PersonalEvent, error) {
+		return append(events, e), nil
+	})
+	return e, err
+}
+
+func updatePersonalEvent(e PersonalEvent) (PersonalEvent, error) {
+	e, err := normalizePersonalEvent(e)
+	if err != nil {
+		return e, err
+	}
+
+	err = updatePersonalEvents(func(events []PersonalEvent) ([]PersonalEvent, error) {
+		for i := range events {
+			if events[i].Id != e.Id {
+				continue
+			}
+			// A new reminder time re-arms the reminder.
+			e.Reminded = events[i].Reminded && events[i].RemindAt == e.RemindAt
+			events[i] = e
+			return events, nil
+		}
+		return nil, errors.New("일정을 찾을 수 없습니다")
+	})
+	return e, err
+}
+
+func deletePersonalEvent(id string) error {
+	return updatePersonalEvents(func(events []PersonalEvent) ([]PersonalEvent, error) {
+		remaining := []PersonalEvent{}
+		for _, e := range events {
+			if e.Id != id {
+				remaining = append(remaining, e)
+			}
+		}
+		return remaining, nil
+	})
+}
+
+// personalScheduleEvents converts stored items that are still current on
+// `today` (YYYYMMDD) into ScheduleEvents for mergeEvents.
+func personalScheduleEvents(events []PersonalEvent, today string) []ScheduleEvent {
+	var out []ScheduleEvent
+	for _, e := range events {
+		last := e.Date
+		if e.EndDate != "" {
+			last = e.EndDate
+		}
+		if last < today {
+			continue
+		}
+		out = append(out, ScheduleEvent{
+			Date:    e.Date,
+			EndDate: e.EndDate,
+			Name:    e.Name,
+			Detail:  e.Detail,
+			Source:  "personal",
+			UID:     "personal-" + e.Id + "@wall-e",
+		})
+	}
+	return out
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// --- normalizePersonalEvent ---
+
+func TestNormalizePersonalEvent_AcceptsDashedDate(t *testing.T) {
+	e, err := normalizePersonalEvent(PersonalEvent{Date: "2026-03-05", Name: " 학부모 상담 "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.Date != "20260305" || e.Name != "학부모 상담" {
+		t.Errorf("got %+v", e)
+	}
+}
+
+func TestNormalizePersonalEvent_Rejects(t *testing.T) {
+	cases := []PersonalEvent{
+		{Date: "20260305", Name: "  "},
+		{Date: "다음주", Name: "상담"},
+		{Date: "20260305", EndDate: "20260301", Name: "상담"},
+		{Date: "20260305", Name: "상담", RemindAt: "내일 아침"},
+	}
+	for _, c := range cases {
+		if _, err := normalizePersonalEvent(c); err == nil {
+			t.Errorf("expected error for %+v", c)
+		}
+	}
+}
+
+func TestNormalizePersonalEvent_SameDayEndDateCleared(t *testing.T) {
+	e, err := normalizePersonalEvent(PersonalEvent{Date: "20260305", EndDate: "2026.03.05", Name: "상담"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if e.EndDate != "" {
+		t.Errorf("EndDate: got %q, want empty", e.EndDate)
+	}
+}
+
+// --- store round trip ---
+
+func TestPersonalEvents_AddUpdateDelete(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	if got := loadPersonalEvents(); len(got) != 0 {
+		t.Fatalf("expected empty store, got %d", len(got))
+	}
+
+	added, err := addPersonalEvent(PersonalEvent{Date: "20260305", Name: "상담", RemindAt: "2026-03-05T08:30"})
+	if err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if added.Id == "" {
+		t.Fatal("expected an id to be assigned")
+	}
+
+	// Mark as reminded, then change the reminder time: it must re-arm.
+	_ = updatePersonalEvents(func(events []PersonalEvent) ([]PersonalEvent, error) {
+		events[0].Reminded = true
+		return events, nil
+	})
+	added.RemindAt = "2026-03-05T09:00"
+	updated, err := updatePersonalEvent(added)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.Reminded {
+		t.Error("changing RemindAt should reset Reminded")
+	}
+
+	if _, err := updatePersonalEvent(PersonalEvent{Id: "missing", Date: "20260305", Name: "x"}); err == nil {
+		t.Error("expected error when updating an unknown id")
+	}
+
+	if err := deletePersonalEvent(added.Id); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if got := loadPersonalEvents(); len(got) != 0 {
+		t.Errorf("expected empty store after delete, got %d", len(got))
+	}
+}
+
+func TestLoadPersonalEvents_InvalidJSON(t *testing.T) {
+	dir, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(personalEventsPath(), []byte("{broken"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	if got := loadPersonalEvents(); got == nil || len(got) != 0 {
+		t.Errorf("expected empty non-nil slice, got %#v", got)
+	}
+
+	// The damaged file is kept aside and not overwritten by the next save.
+	if _, err := addPersonalEvent(PersonalEvent{Date: "20260305", Name: "상담"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	copies, _ := filepath.Glob(personalEventsPath() + ".corrupt-*")
+	if len(copies) != 1 {
+		t.Fatalf("expected 1 corrupt copy, got %v", copies)
+	}
+	if data, err := os.ReadFile(copies[0]); err != nil || string(data) != "{broken" {
+		t.Errorf("corrupt copy: %q, %v", data, err)
+	}
+	if got := loadPersonalEvents(); len(got) != 1 {
+		t.Errorf("expected the new event to be saved, got %d", len(got))
+	}
+}
+
+func TestLoadPersonalEvents_SecondDamagedFileKeepsFirstCopy(t *testing.T) {
+	dir, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	for _, content := range []string{"{first", "{second"} {
+		if err := os.WriteFile(personalEventsPath(), []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile: %v", err)
+		}
+		loadPersonalEvents()
+	}
+
+	copies, _ := filepath.Glob(personalEventsPath() + ".corrupt-*")
+	var contents []string
+	for _, c := range copies {
+		data, _ := os.ReadFile(c)
+		contents = append(contents, string(data))
+	}
+	sort.Strings(contents)
+	if strings.Join(contents, ",") != "{first,{second" {
+		t.Errorf("expected both damaged files kept, got %q", contents)
+	}
+}
+
+// --- personalScheduleEvents ---
+
+func TestPersonalScheduleEvents_SkipsPastAndTagsSource(t *testing.T) {
+	events := []PersonalEvent{
+		{Date: "20260301", Name: "지난 일정"},
+		{Date: "20260301", EndDate: "20260310", Name: "진행 중"},
+		{Date: "20260320", Name: "예정"},
+	}
+
+	got := personalScheduleEvents(events, "20260305")
+
+	if len(got) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(got))
+	}
+	for _, e := range got {
+		if e.Source != "personal" {
+			t.Errorf("Source: got %q, want personal", e.Source)
+		}
+	}
+	if got[0].EndDate != "20260310" {
+		t.Errorf("EndDate not carried over: %+v", got[0])
+	}
+}
+
+// --- dueReminders ---
+
+func TestDueReminders(t *testing.T) {
+	now := time.Date(2026, 3, 5, 9, 0, 0, 0, time.Local)
+	events := []PersonalEvent{
+		{Name: "지남", RemindAt: "2026-03-05T08:59"},
+		{Name: "정각", RemindAt: "2026-03-05T09:00"},
+		{Name: "이미 알림", RemindAt: "2026-03-05T08:00", Reminded: true},
+		{Name: "나중", RemindAt: "2026-03-05T10:00"},
+		{Name: "더 나중", RemindAt: "2026-03-06T10:00"},
+		{Name: "알림 없음"},
+	}
+
+	due, next := dueReminders(events, now)
+
+	if len(due) != 2 || due[0] != 0 || due[1] != 1 {
+		t.Errorf("due: got %v, want [0 1]", due)
+	}
+	if want := time.Date(2026, 3, 5, 10, 0, 0, 0, time.Local); !next.Equal(want) {
+		t.Errorf("next: got %v, want %v", next, want)
+	}
+}
+
+func TestFireDueReminders_MarksAndPersists(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	if _, err := addPersonalEvent(PersonalEvent{Date: "20260305", Name: "상담", RemindAt: "2026-03-05T08:30"}); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	now := time.Date(2026, 3, 5, 8, 31, 0, 0, time.Local)
+
+	fired, _, err := fireDueReminders(now)
+	if err != nil {
+		t.Fatalf("fireDueReminders: %v", err)
+	}
+	if len(fired) != 1 {
+		t.Fatalf("expected 1 fired reminder, got %d", len(fired))
+	}
+
+	// A second pass must not fire the same reminder again.
+	if again, _, _ := fireDueReminders(now); len(again) != 0 {
+		t.Errorf("reminder fired twice")
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"regexp"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The optional admin PIN keeps students out of a wall display. When
+// Settings.AdminPINHash is set, every binding that changes settings, stored
+// data or the machine needs the PIN: opening and saving settings, media and
+// countdown edits, personal events, auto start, updates, closing the window
+// and quitting from the tray. Exempt are reads, the file pickers (an import
+// only takes effect through SaveSettings) and "no bells today", a daily
+// classroom control that resets at midnight. A correct PIN unlocks the
+// guarded actions for adminUnlockDuration, extended by every one of them. A
+// forgotten PIN is cleared by starting the app with resetPINFlag while no
+// other instance is running.
+const (
+	adminUnlockDuration = 10 * time.Minute
+	pinFreeAttempts     = 5                // failures before lockouts start
+	pinBaseLockout      = 30 * time.Second // doubled for every further failure
+	pinMaxLockout       = 15 * time.Minute
+	resetPINFlag        = "--reset-pin"
+)
+
+var pinPattern = regexp.MustCompile(`^[0-9]{4,8}$`)
+
+// PINStatus tells the UI whether to ask for the PIN.
+type PINStatus struct {
+	Enabled    bool `json:"enabled"`
+	Unlocked   bool `json:"unlocked"`
+	RetryAfter int  `json:"retryAfter"` // seconds until another attempt is accepted
+}
+
+// adminLock is the unlock session and the failed-attempt counter. Both live
+// in memory only, so a restart locks the app again.
+type adminLock struct {
+	mu            sync.Mutex
+	unlockedUntil time.Time
+	failures      int
+	blockedUntil  time.Time
+}
+
+func hashPIN(pin string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+func (l *adminLock) status(hash string, now time.Time) PINStatus {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	st := PINStatus{Enabled: hash != "", Unlocked: hash == "" || now.Before(l.unlockedUntil)}
+	if now.Before(l.blockedUntil) {
+		st.RetryAfter = ceilSeconds(l.blockedUntil.Sub(now))
+	}
+	return st
+}
+
+// allowed reports whether a guarded action may run, extending the session.
+func (l *adminLock) allowed(hash string, now time.Time) bool {
+	if hash == "" {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if now.Before(l.unlockedUntil) {
+		l.unlockedUntil = now.Add(adminUnlockDuration)
+		return true
+	}
+	return false
+}
+
+// unlock checks pin against hash and starts a session when it matches. On
+// failure it returns how long further attempts are refused, if at all.
+func (l *adminLock) unlock(hash, pin string, now time.Time) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if hash == "" {
+		return true, 0
+	}
+	if now.Before(l.blockedUntil) {
+		return false, l.blockedUntil.Sub(now)
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(pin)) != nil {
+		l.failures++
+		if extra := l.failures - pinFreeAttempts; extra >= 0 {
+			lockout := pinMaxLockout
+			if extra < 10 {
+				lockout = min(pinBaseLockout<<extra, pinMaxLockout)
+			}
+			l.blockedUntil = now.Add(lockout)
+			return false, lockout
+		}
+		return false, 0
+	}
+	l.failures = 0
+	l.blockedUntil = time.Time{}
+	l.unlockedUntil = now.Add(adminUnlockDuration)
+	return true, 0
+}
+
+func (l *adminLock) lock() {
+	l.mu.Lock()
+	l.unlockedUntil = time.Time{}
+	l.mu.Unlock()
+}
+
+// resetAdminPIN clears the PIN in settings.json, for resetPINFlag. A PIN
+// pinned by the admin policy still applies.
+func resetAdminPIN() error {
+	_, err := updateSettings(func(s *Settings) bool {
+		if s.AdminPINHash == "" {
+			return false
+		}
+		s.AdminPINHash = ""
+		return true
+	})
+	return err
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
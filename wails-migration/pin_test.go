@@ -0,0 +1,158 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func testPINHash(t *testing.T, pin string) string {
+	t.Helper()
+	hash, err := hashPIN(pin)
+	if err != nil {
+		t.Fatalf("hashPIN: %v", err)
+	}
+	return hash
+}
+
+// --- adminLock ---
+
+func TestAdminLock_NoPINAlwaysAllowed(t *testing.T) {
+	var l adminLock
+	now := time.Now()
+	if !l.allowed("", now) {
+		t.Error("expected allowed without a PIN")
+	}
+	if st := l.status("", now); st.Enabled || !st.Unlocked {
+		t.Errorf("status: got %+v", st)
+	}
+}
+
+func TestAdminLock_UnlockSession(t *testing.T) {
+	var l adminLock
+	hash := testPINHash(t, "1234")
+	now := time.Now()
+
+	if l.allowed(hash, now) {
+		t.Fatal("expected locked before unlock")
+	}
+	if ok, _ := l.unlock(hash, "1234", now); !ok {
+		t.Fatal("correct PIN rejected")
+	}
+	if !l.allowed(hash, now.Add(adminUnlockDuration-time.Second)) {
+		t.Error("expected unlocked within the session")
+	}
+	// allowed extends the session.
+	if !l.allowed(hash, now.Add(2*adminUnlockDuration-2*time.Second)) {
+		t.Error("expected the session to be extended")
+	}
+	l.lock()
+	if l.allowed(hash, now) {
+		t.Error("expected locked after lock")
+	}
+}
+
+func TestAdminLock_RateLimit(t *testing.T) {
+	var l adminLock
+	hash := testPINHash(t, "1234")
+	now := time.Now()
+
+	for i := 1; i < pinFreeAttempts; i++ {
+		if ok, retry := l.unlock(hash, "0000", now); ok || retry != 0 {
+			t.Fatalf("attempt %d: got %v, %v", i, ok, retry)
+		}
+	}
+	if _, retry := l.unlock(hash, "0000", now); retry != pinBaseLockout {
+		t.Fatalf("lockout: got %v, want %v", retry, pinBaseLockout)
+	}
+	// Even the right PIN is refused while locked out.
+	if ok, _ := l.unlock(hash, "1234", now.Add(time.Second)); ok {
+		t.Fatal("expected refusal during lockout")
+	}
+	if st := l.status(hash, now.Add(time.Second)); st.RetryAfter != 29 {
+		t.Errorf("RetryAfter: got %d, want 29", st.RetryAfter)
+	}
+
+	now = now.Add(pinBaseLockout)
+	if _, retry := l.unlock(hash, "0000", now); retry != 2*pinBaseLockout {
+		t.Errorf("second lockout: got %v, want %v", retry, 2*pinBaseLockout)
+	}
+	now = now.Add(2 * pinBaseLockout)
+	if ok, _ := l.unlock(hash, "1234", now); !ok {
+		t.Fatal("correct PIN rejected after the lockout")
+	}
+	if l.failures != 0 {
+		t.Errorf("failures not reset: %d", l.failures)
+	}
+}
+
+func TestAdminLock_LockoutCapped(t *testing.T) {
+	l := adminLock{failures: pinFreeAttempts + 40}
+	hash := testPINHash(t, "1234")
+	if _, retry := l.unlock(hash, "0000", time.Now()); retry != pinMaxLockout {
+		t.Errorf("got %v, want %v", retry, pinMaxLockout)
+	}
+}
+
+// --- resetAdminPIN ---
+
+func TestResetAdminPIN(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	s := defaultSettings
+	s.SchoolName = "학교"
+	s.AdminPINHash = testPINHash(t, "1234")
+	if err := saveSettings(s); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := resetAdminPIN(); err != nil {
+		t.Fatalf("resetAdminPIN: %v", err)
+	}
+	got := loadSettings()
+	if got.AdminPINHash != "" || got.SchoolName != "학교" {
+		t.Errorf("got hash %q, school %q", got.AdminPINHash, got.SchoolName)
+	}
+}
+
+func TestGuardedBindings_NeedPIN(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	s := defaultSettings
+	s.AdminPINHash = testPINHash(t, "1234")
+	s.CustomBackgrounds = []CustomBackground{{Id: "bg", FileName: "bg.jpg"}}
+	s.CustomSounds = []CustomSound{{Id: "snd", FileName: "snd.wav"}}
+	s.Countdowns = []Countdown{{Id: "cd", Name: "수능", Date: "20261119"}}
+	if err := saveSettings(s); err != nil {
+		t.Fatal(err)
+	}
+
+	a := NewApp("")
+	for name, got := range map[string]string{
+		"RemoveCustomBackground":  a.RemoveCustomBackground("bg"),
+		"RemoveCustomSound":       a.RemoveCustomSound("snd"),
+		"AddCountdown":            a.AddCountdown("기말고사", "20261201"),
+		"RemoveCountdown":         a.RemoveCountdown("cd"),
+		"ExportSettings(secrets)": a.ExportSettings(true),
+		"SetAutoStart":            a.SetAutoStart(true),
+		"DownloadAndRunUpdate":    a.DownloadAndRunUpdate("https://example.com/setup.exe"),
+		"DeletePersonalEvent":     a.DeletePersonalEvent("ev"),
+		"AddPersonalEvent":        a.AddPersonalEvent(PersonalEvent{Date: "20261201", Name: "상담"}).Error,
+		"UpdatePersonalEvent":     a.UpdatePersonalEvent(PersonalEvent{Id: "ev", Date: "20261201", Name: "상담"}).Error,
+	} {
+		if got != pinRequiredMessage {
+			t.Errorf("%s = %q, want the PIN message", name, got)
+		}
+	}
+	if r := a.CleanupBackgrounds(true); len(r.Errors) != 1 || r.Errors[0] != pinRequiredMessage {
+		t.Errorf("CleanupBackgrounds = %+v", r)
+	}
+
+	got := loadSettings()
+	if len(got.CustomBackgrounds) != 1 || len(got.CustomSounds) != 1 || len(got.Countdowns) != 1 {
+		t.Errorf("settings changed without the PIN: %+v", got)
+	}
+	if events := loadPersonalEvents(); len(events) != 0 {
+		t.Errorf("personal events changed without the PIN: %+v", events)
+	}
+}
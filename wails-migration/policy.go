@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// policyFileName is the optional machine-wide policy that school IT deploys
+// to pin settings on every classroom PC. It uses the keys of settings.json;
+// each key it sets overrides the user's value and is locked in the UI:
+//
+//	{"schoolCode": "7010057", "officeCode": "B10", "updateChannel": "stable"}
+const policyFileName = "policy.json"
+
+// Policy holds the settings pinned by a policy file.
+type Policy struct {
+	Path   string
+	values map[string]json.RawMessage
+}
+
+// activePolicy is loaded once at startup; nil when there is no policy file.
+var activePolicy *Policy
+
+// policyPaths lists where a policy file is looked for, in order: next to the
+// executable, then %ProgramData%\Wall-E. Both need admin rights to write.
+func policyPaths() []string {
+	var paths []string
+	if exe, err := os.Executable(); err == nil {
+		paths = append(paths, filepath.Join(filepath.Dir(exe), policyFileName))
+	}
+	if dir := os.Getenv("ProgramData"); dir != "" {
+		paths = append(paths, filepath.Join(dir, "Wall-E", policyFileName))
+	}
+	return paths
+}
+
+// loadPolicy reads the first policy file that exists. It returns nil when
+// there is none.
+func loadPolicy(paths []string) (*Policy, error) {
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return parsePolicy(path, data)
+	}
+	return nil, nil
+}
+
+// parsePolicy rejects unknown keys and values of the wrong type, so a typo
+// in the policy is reported instead of silently leaving a field unlocked.
+func parsePolicy(path string, data []byte) (*Policy, error) {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	known := settingsFields(defaultSettings)
+	for key := range values {
+		if _, ok := known[key]; !ok || key == "schemaVersion" {
+			return nil, fmt.Errorf("%s: unknown setting %q", path, key)
+		}
+	}
+	p := &Policy{Path: path, values: values}
+	s := defaultSettings
+	if err := p.apply(&s); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// apply overrides the policy's fields in s.
+func (p *Policy) apply(s *Settings) error {
+	if p == nil || len(p.values) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(p.values)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, s)
+}
+
+// lockedFields returns the locked settings keys, sorted.
+func (p *Policy) lockedFields() []string {
+	if p == nil {
+		return []string{}
+	}
+	keys := make([]string, 0, len(p.values))
+	for key := range p.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// changedFields returns the locked keys whose value in s differs from the
+// policy.
+func (p *Policy) changedFields(s Settings) []string {
+	if p == nil {
+		return nil
+	}
+	enforced := s
+	if err := p.apply(&enforced); err != nil {
+		return p.lockedFields()
+	}
+	got, want := settingsFields(s), settingsFields(enforced)
+	var changed []string
+	for _, key := range p.lockedFields() {
+		if !bytes.Equal(got[key], want[key]) {
+			changed = append(changed, key)
+		}
+	}
+	return changed
+}
+
+// settingsFields returns s as JSON values keyed by settings.json key.
+func settingsFields(s Settings) map[string]json.RawMessage {
+	var m map[string]json.RawMessage
+	data, _ := json.Marshal(s)
+	_ = json.Unmarshal(data, &m)
+	return m
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writePolicy(t *testing.T, dir, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, policyFileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// --- loadPolicy ---
+
+func TestLoadPolicy_FirstFileWins(t *testing.T) {
+	exeDir, programData := t.TempDir(), t.TempDir()
+	writePolicy(t, programData, `{"schoolCode": "2222222"}`)
+
+	p, err := loadPolicy([]string{filepath.Join(exeDir, policyFileName), filepath.Join(programData, policyFileName)})
+	if err != nil || p == nil {
+		t.Fatalf("got %v, %v", p, err)
+	}
+	if p.Path != filepath.Join(programData, policyFileName) {
+		t.Errorf("Path: got %q", p.Path)
+	}
+
+	writePolicy(t, exeDir, `{"schoolCode": "1111111"}`)
+	p, _ = loadPolicy([]string{filepath.Join(exeDir, policyFileName), filepath.Join(programData, policyFileName)})
+	s := defaultSettings
+	p.apply(&s)
+	if s.SchoolCode != "1111111" {
+		t.Errorf("SchoolCode: got %q, want the file next to the executable", s.SchoolCode)
+	}
+}
+
+func TestLoadPolicy_None(t *testing.T) {
+	p, err := loadPolicy([]string{filepath.Join(t.TempDir(), policyFileName)})
+	if p != nil || err != nil {
+		t.Errorf("got %v, %v", p, err)
+	}
+	if got := p.lockedFields(); len(got) != 0 {
+		t.Errorf("nil policy locks %v", got)
+	}
+}
+
+func TestParsePolicy_Rejects(t *testing.T) {
+	cases := map[string]string{
+		"invalid json":   `{"schoolCode":`,
+		"unknown key":    `{"schoolcode": "7010057"}`,
+		"schema version": `{"schemaVersion": 1}`,
+		"wrong type":     `{"grade": "3"}`,
+	}
+	for name, content := range cases {
+		if _, err := parsePolicy(policyFileName, []byte(content)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+// --- enforcement ---
+
+func TestLoadSettings_AppliesPolicy(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	p, err := parsePolicy(policyFileName, []byte(`{"schoolCode": "7010057", "customApiKey": "school-key", "updateChannel": "off"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	activePolicy = p
+	defer func() { activePolicy = nil }()
+
+	if err := saveSettings(Settings{SchoolCode: "1234567", CustomAPIKey: "student-key", Grade: 2}); err != nil {
+		t.Fatal(err)
+	}
+	s := loadSettings()
+
+	if s.SchoolCode != "7010057" || s.CustomAPIKey != "school-key" || s.UpdateChannel != "off" {
+		t.Errorf("policy not applied: %q %q %q", s.SchoolCode, s.CustomAPIKey, s.UpdateChannel)
+	}
+	if s.Grade != 2 {
+		t.Errorf("unlocked field overridden: Grade %d", s.Grade)
+	}
+	want := []string{"customApiKey", "schoolCode", "updateChannel"}
+	if got := p.lockedFields(); !reflect.DeepEqual(got, want) {
+		t.Errorf("lockedFields: got %v, want %v", got, want)
+	}
+}
+
+func TestPolicy_ChangedFields(t *testing.T) {
+	p, err := parsePolicy(policyFileName, []byte(`{"schoolCode": "7010057", "alarmEnabled": false}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := defaultSettings
+	p.apply(&s)
+
+	if got := p.changedFields(s); len(got) != 0 {
+		t.Errorf("unchanged settings reported %v", got)
+	}
+	s.Grade = 5
+	if got := p.changedFields(s); len(got) != 0 {
+		t.Errorf("unlocked change reported %v", got)
+	}
+	s.SchoolCode = "0000000"
+	if got := p.changedFields(s); !reflect.DeepEqual(got, []string{"schoolCode"}) {
+		t.Errorf("got %v, want [schoolCode]", got)
+	}
+}
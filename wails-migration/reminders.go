@@ -0,0 +1,99 @@
+package main
+
+import (
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// reminderPollInterval bounds how long the loop sleeps, so that a changed
+// system clock or a wake from sleep is noticed within a minute.
+const reminderPollInterval = time.Minute
+
+// dueReminders returns the indexes of events whose reminder is due at `now`
+// and the time of the next pending reminder (zero if none).
+func dueReminders(events []PersonalEvent, now time.Time) ([]int, time.Time) {
+	var due []int
+	var next time.Time
+	for i, e := range events {
+		if e.RemindAt == "" || e.Reminded {
+			continue
+		}
+		at, err := time.ParseInLocation(remindAtLayout, e.RemindAt, time.Local)
+		if err != nil {
+			continue
+		}
+		if !at.After(now) {
+			due = append(due, i)
+		} else if next.IsZero() || at.Before(next) {
+			next = at
+		}
+	}
+	return due, next
+}
+
+// fireDueReminders marks due reminders as sent, persists that, and returns
+// the events to announce plus the next reminder time. Nothing is announced
+// if the "sent" marks can't be saved; the loop tries again on its next pass
+// rather than announcing a reminder that would fire again after a restart.
+func fireDueReminders(now time.Time) ([]PersonalEvent, time.Time, error) {
+	due, next := dueReminders(loadPersonalEvents(), now)
+	if len(due) == 0 {
+		return nil, next, nil
+	}
+
+	var fired []PersonalEvent
+	err := updatePersonalEvents(func(events []PersonalEvent) ([]PersonalEvent, error) {
+		// Re-check under the lock in case the list changed in between.
+		due, next = dueReminders(events, now)
+		for _, i := range due {
+			events[i].Reminded = true
+			fired = append(fired, events[i])
+		}
+		return events, nil
+	})
+	if err != nil {
+		return nil, next, err
+	}
+	return fired, next, nil
+}
+
+// runReminders emits a "reminder" event for each personal event whose
+// reminder time has arrived. Reminders missed while the app was closed fire
+// once on the next start.
+func (a *App) runReminders() {
+	for {
+		fired, next, err := fireDueReminders(time.Now())
+		if err != nil {
+			runtime.LogError(a.ctx, "Failed to save sent reminders: "+err.Error())
+		}
+		for _, e := range fired {
+			runtime.EventsEmit(a.ctx, "reminder", e)
+		}
+
+		wait := reminderPollInterval
+		if !next.IsZero() {
+			if d := time.Until(next); d < wait {
+				wait = d
+			}
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-a.quit:
+			timer.Stop()
+			return
+		case <-a.remindersChanged:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+// notifyRemindersChanged wakes the reminder loop after the store was edited.
+func (a *App) notifyRemindersChanged() {
+	select {
+	case a.remindersChanged <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Secrets in settings.json (the personal NEIS API key and the proxy URL,
+// which may carry a password) are sealed with AES-256-GCM under a key
+// derived from machine-local data, so a copied settings.json or bundle does
+// not carry a usable secret. In memory, Settings always holds the plain
+// value; saveSettings seals and decodeSettings opens.
+const sealedSecretPrefix = "enc:v1:"
+
+// secretMask replaces all but the last characters of a secret shown in the UI.
+const secretMask = "••••••••"
+
+var secretKey = sync.OnceValue(func() []byte {
+	id := machineID()
+	if id == "" {
+		// Still tied to this PC, just weaker than the machine GUID.
+		id, _ = os.Hostname()
+	}
+	sum := sha256.Sum256([]byte("wall-e settings secrets v1\x00" + id))
+	return sum[:]
+})
+
+// sealSecret encrypts plain. The nonce is derived from the plaintext, so the
+// same key always seals to the same string and an unchanged save does not
+// rewrite settings.json.
+func sealSecret(plain string) (string, error) {
+	if plain == "" || strings.HasPrefix(plain, sealedSecretPrefix) {
+		return plain, nil
+	}
+	aead, err := secretAEAD()
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, secretKey())
+	mac.Write([]byte(plain))
+	nonce := mac.Sum(nil)[:aead.NonceSize()]
+	sealed := aead.Seal(nonce, nonce, []byte(plain), nil)
+	return sealedSecretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// openSecret decrypts a sealed value. Values without the prefix are legacy
+// plaintext and returned unchanged.
+func openSecret(value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, sealedSecretPrefix)
+	if !ok {
+		return value, nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	aead, err := secretAEAD()
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("sealed secret too short")
+	}
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("secret was sealed on another PC")
+	}
+	return string(plain), nil
+}
+
+func secretAEAD() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(secretKey())
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealSecrets and openSecrets convert every secret field of s. A secret that
+// cannot be opened, e.g. in a file copied from another PC, is cleared so the
+// user enters it again.
+func sealSecrets(s *Settings) error {
+	for _, field := range []*string{&s.CustomAPIKey, &s.ProxyURL} {
+		sealed, err := sealSecret(*field)
+		if err != nil {
+			return err
+		}
+		*field = sealed
+	}
+	return nil
+}
+
+func openSecrets(s *Settings) error {
+	var errs []error
+	for _, field := range []*string{&s.CustomAPIKey, &s.ProxyURL} {
+		plain, err := openSecret(*field)
+		*field = plain
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// clearSecrets removes secrets and the PIN hash, e.g. from an export. The
+// proxy keeps its address and user name.
+func clearSecrets(s *Settings) {
+	s.UseCustomAPIKey = false
+	s.CustomAPIKey = ""
+	s.AdminPINHash = ""
+	if u, err := parseProxyURL(s.ProxyURL); err == nil && u.User != nil {
+		u.User = url.User(u.User.Username())
+		s.ProxyURL = u.String()
+	} else if err != nil {
+		s.ProxyURL = redactUserinfo(s.ProxyURL)
+	}
+}
+
+// maskSecret shows only the last four characters of a secret.
+func maskSecret(plain string) string {
+	if plain == "" {
+		return ""
+	}
+	r := []rune(plain)
+	if len(r) <= 4 {
+		return secretMask
+	}
+	return secretMask + string(r[len(r)-4:])
+}
+
+// migrateSealSecrets (schema 2 -> 3) seals the API key that older versions
+// stored in plaintext.
+func migrateSealSecrets(m map[string]any) error {
+	key, _ := m["customApiKey"].(string)
+	if key == "" {
+		return nil
+	}
+	sealed, err := sealSecret(key)
+	if err != nil {
+		return err
+	}
+	m["customApiKey"] = sealed
+	return nil
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// --- sealSecret / openSecret ---
+
+func TestSealSecret_RoundTrip(t *testing.T) {
+	sealed, err := sealSecret("my-neis-key")
+	if err != nil {
+		t.Fatalf("sealSecret: %v", err)
+	}
+	if !strings.HasPrefix(sealed, sealedSecretPrefix) || strings.Contains(sealed, "my-neis-key") {
+		t.Fatalf("not sealed: %q", sealed)
+	}
+	again, _ := sealSecret("my-neis-key")
+	if again != sealed {
+		t.Error("sealing the same key twice should give the same string")
+	}
+	if resealed, _ := sealSecret(sealed); resealed != sealed {
+		t.Error("a sealed value should not be sealed again")
+	}
+
+	plain, err := openSecret(sealed)
+	if err != nil || plain != "my-neis-key" {
+		t.Errorf("openSecret: got %q, %v", plain, err)
+	}
+}
+
+func TestOpenSecret_PlaintextAndTampered(t *testing.T) {
+	if plain, err := openSecret("legacy-key"); err != nil || plain != "legacy-key" {
+		t.Errorf("legacy plaintext: got %q, %v", plain, err)
+	}
+	sealed, _ := sealSecret("my-neis-key")
+	tampered := sealed[:len(sealed)-4] + "AAAA"
+	if plain, err := openSecret(tampered); err == nil || plain != "" {
+		t.Errorf("tampered: got %q, %v", plain, err)
+	}
+}
+
+func TestMaskSecret(t *testing.T) {
+	cases := map[string]string{
+		"":             "",
+		"abc":          secretMask,
+		"abcdef123456": secretMask + "3456",
+	}
+	for in, want := range cases {
+		if got := maskSecret(in); got != want {
+			t.Errorf("maskSecret(%q): got %q, want %q", in, got, want)
+		}
+	}
+}
+
+// --- settings.json ---
+
+func TestSaveSettings_SealsAPIKey(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	if err := saveSettings(Settings{UseCustomAPIKey: true, CustomAPIKey: "my-neis-key"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("my-neis-key")) {
+		t.Errorf("API key stored in plaintext: %s", data)
+	}
+	if s := loadSettings(); s.CustomAPIKey != "my-neis-key" {
+		t.Errorf("CustomAPIKey: got %q", s.CustomAPIKey)
+	}
+}
+
+func TestLoadSettings_SealsLegacyPlaintextKey(t *testing.T) {
+	dir, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	writeRawSettings(t, dir, `{"schemaVersion":2,"useCustomApiKey":true,"customApiKey":"my-neis-key"}`)
+
+	if s := loadSettings(); s.CustomAPIKey != "my-neis-key" {
+		t.Errorf("CustomAPIKey: got %q", s.CustomAPIKey)
+	}
+	data, _ := os.ReadFile(settingsPath)
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if key, _ := m["customApiKey"].(string); !strings.HasPrefix(key, sealedSecretPrefix) {
+		t.Errorf("upgraded file not sealed: %s", data)
+	}
+}
+
+// --- bundles ---
+
+func TestSettingsBundle_LeavesOutSecrets(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	s := defaultSettings
+	s.UseCustomAPIKey, s.CustomAPIKey = true, "my-neis-key"
+	s.AdminPINHash = "hash"
+
+	var buf bytes.Buffer
+	if err := writeSettingsBundle(&buf, s, false); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readSettingsBundle(bundleReader(t, buf.Bytes()), defaultSettings, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.CustomAPIKey != "" || got.UseCustomAPIKey || got.AdminPINHash != "" {
+		t.Errorf("secrets exported: %q %v %q", got.CustomAPIKey, got.UseCustomAPIKey, got.AdminPINHash)
+	}
+}
@@ -8,39 +8,67 @@ import (
 )
 
 type CustomBackground struct {
-	Id       string `json:"id"`
-	Name     string `json:"name"`
-	FileName string `json:"fileName"`
+	Id            string `json:"id"`
+	Name          string `json:"name"`
+	FileName      string `json:"fileName"`
+	ThumbFileName string `json:"thumbFileName,omitempty"` // in backgrounds/thumbs
+	Hash          string `json:"hash,omitempty"`          // sha256 of the original file, for dedupe
 }
 
 type Settings struct {
-	SchoolName        string             `json:"schoolName"`
-	SchoolCode        string             `json:"schoolCode"`
-	OfficeCode        string             `json:"officeCode"`
-	Grade             int                `json:"grade"`
-	ClassNum          int                `json:"classNum"`
-	Latitude          float64            `json:"latitude"`
-	Longitude         float64            `json:"longitude"`
-	SpreadsheetURL    string             `json:"spreadsheetUrl"`
-	UseCustomAPIKey   bool               `json:"useCustomApiKey"`
-	CustomAPIKey      string             `json:"customApiKey"`
-	AlarmEnabled      bool               `json:"alarmEnabled"`
-	AlarmSound        string             `json:"alarmSound"`
-	CustomAlarmData   string             `json:"customAlarmData"`
-	CustomAlarmName   string             `json:"customAlarmName"`
-	BackgroundID      string             `json:"backgroundId"`
-	CustomBackgrounds []CustomBackground `json:"customBackgrounds"`
+	SchemaVersion      int                `json:"schemaVersion"`
+	SchoolName         string             `json:"schoolName"`
+	SchoolCode         string             `json:"schoolCode"`
+	OfficeCode         string             `json:"officeCode"`
+	Grade              int                `json:"grade"`
+	ClassNum           int                `json:"classNum"`
+	Latitude           float64            `json:"latitude"`
+	Longitude          float64            `json:"longitude"`
+	SpreadsheetURL     string             `json:"spreadsheetUrl"`
+	ICSSources         []string           `json:"icsSources"`
+	EventLimit         int                `json:"eventLimit"`
+	EventWindowDays    int                `json:"eventWindowDays"`
+	UseCustomAPIKey    bool               `json:"useCustomApiKey"`
+	CustomAPIKey       string             `json:"customApiKey"` // sealed on disk, see secrets.go
+	AlarmEnabled       bool               `json:"alarmEnabled"`
+	AlarmSound         string             `json:"alarmSound"` // preset name or "custom:<id>"
+	BellSounds         BellSounds         `json:"bellSounds"`
+	CustomSounds       []CustomSound      `json:"customSounds"`
+	Bells              BellConfig         `json:"bells"`
+	MuteBellsDate      string             `json:"muteBellsDate"` // YYYYMMDD of a manual "no bells today"
+	BackgroundID       string             `json:"backgroundId"`
+	BackgroundRotation BackgroundRotation `json:"backgroundRotation"`
+	CustomBackgrounds  []CustomBackground `json:"customBackgrounds"`
+	Countdowns         []Countdown        `json:"countdowns"`
+	UpdateChannel      string             `json:"updateChannel"` // "stable" (or ""), "beta" or "off"
+	ProxyURL           string             `json:"proxyUrl"`      // [user:password@]host:port; sealed on disk
+	ProxyPACURL        string             `json:"proxyPacUrl"`   // PAC script picking the proxy, instead of ProxyURL
+	NoProxy            string             `json:"noProxy"`       // comma-separated hosts, .domains and CIDRs reached directly
+	CACertFiles        []string           `json:"caCertFiles"`   // extra trusted root certificates (PEM)
+	AdminPINHash       string             `json:"adminPinHash"`  // bcrypt hash; empty disables the PIN
 }
 
 var defaultSettings = Settings{
-	AlarmEnabled: true,
-	AlarmSound:   "classic",
+	SchemaVersion: currentSchemaVersion,
+	EventLimit:    30,
+	AlarmEnabled:  true,
+	AlarmSound:    "classic",
+	Bells:         defaultBellConfig,
+}
+
+// eventLimit returns the dashboard event cap; 0 disables that dimension.
+func (s Settings) eventLimit() EventLimit {
+	return EventLimit{MaxCount: s.EventLimit, WindowDays: s.EventWindowDays}
 }
 
 var (
-	settingsMu   sync.Mutex
-	settingsDir  string
-	settingsPath string
+	settingsMu sync.Mutex
+	// settingsUpdateMu serializes updateSettings, so that the settings
+	// window, the tray and the background loops can't drop each other's
+	// changes.
+	settingsUpdateMu sync.Mutex
+	settingsDir      string
+	settingsPath     string
 )
 
 func init() {
@@ -53,17 +81,27 @@ func init() {
 	settingsPath = filepath.Join(settingsDir, "settings.json")
 }
 
+// loadSettings returns the saved settings with the fields locked by
+// activePolicy overridden.
 func loadSettings() Settings {
+	s := readSettings()
+	activePolicy.apply(&s)
+	return s
+}
+
+func readSettings() Settings {
 	settingsMu.Lock()
 	defer settingsMu.Unlock()
 
-	s := defaultSettings
 	data, err := os.ReadFile(settingsPath)
 	if err != nil {
-		return s
+		return defaultSettings
 	}
-	_ = json.Unmarshal(data, &s)
-	return s
+	if !json.Valid(data) {
+		// Typically a save cut short by a power loss.
+		return recoverSettings(data)
+	}
+	return decodeSettings(data)
 }
 
 func saveSettings(s Settings) error {
@@ -73,9 +111,27 @@ func saveSettings(s Settings) error {
 	if err := os.MkdirAll(settingsDir, 0755); err != nil {
 		return err
 	}
+	s.SchemaVersion = currentSchemaVersion
+	if err := sealSecrets(&s); err != nil {
+		return err
+	}
 	data, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(settingsPath, data, 0644)
+	return writeSettingsFile(data)
+}
+
+// updateSettings loads the settings, lets fn change them and saves the
+// result if fn returns true. Every load-modify-save of settings.json goes
+// through here. It returns the settings as fn left them.
+func updateSettings(fn func(s *Settings) bool) (Settings, error) {
+	settingsUpdateMu.Lock()
+	defer settingsUpdateMu.Unlock()
+
+	s := loadSettings()
+	if !fn(&s) {
+		return s, nil
+	}
+	return s, saveSettings(s)
 }
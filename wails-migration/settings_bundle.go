@@ -0,0 +1,253 @@
+package main
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// A settings bundle is a zip holding settings.json and the custom
+// backgrounds and sounds it refers to, so one configured PC can serve as the
+// template for every classroom:
+//
+//	settings.json
+//	backgrounds/<fileName>
+//	backgrounds/thumbs/<thumbFileName>
+//	sounds/<fileName>
+const (
+	bundleSettingsName = "settings.json"
+	bundleMaxFileSize  = 64 << 20 // per entry, guards against zip bombs
+)
+
+// ImportOptions selects which local-only fields survive an import.
+type ImportOptions struct {
+	KeepClass  bool `json:"keepClass"`  // keep this PC's grade and class
+	KeepAPIKey bool `json:"keepApiKey"` // keep this PC's NEIS API key
+}
+
+// writeSettingsBundle writes s and its media files to w. Media files that no
+// longer exist are left out; the import drops their entries. Secrets are
+// only included when includeSecrets is set, and then in plaintext, since
+// the machine key does not travel.
+func writeSettingsBundle(w io.Writer, s Settings, includeSecrets bool) error {
+	zw := zip.NewWriter(w)
+
+	s.SchemaVersion = currentSchemaVersion
+	s.MuteBellsDate = ""
+	if !includeSecrets {
+		clearSecrets(&s)
+	}
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	f, err := zw.Create(bundleSettingsName)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+
+	add := func(name, path string) error {
+		src, err := os.Open(path)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+		dst, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(dst, src)
+		return err
+	}
+	for _, bg := range s.CustomBackgrounds {
+		if err := add("backgrounds/"+bg.FileName, filepath.Join(backgroundsDir(), bg.FileName)); err != nil {
+			return err
+		}
+		if bg.ThumbFileName != "" {
+			if err := add("backgrounds/thumbs/"+bg.ThumbFileName, filepath.Join(backgroundThumbsDir(), bg.ThumbFileName)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, cs := range s.CustomSounds {
+		if err := add("sounds/"+cs.FileName, filepath.Join(soundsDir(), cs.FileName)); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// readSettingsBundle returns the settings in a bundle, upgraded to the
+// current schema. Its media files are copied into settingsDir; entries whose
+// file is missing from the bundle are dropped. Fields selected by opts, and
+// the day-specific MuteBellsDate, are taken from local.
+func readSettingsBundle(zr *zip.Reader, local Settings, opts ImportOptions) (Settings, error) {
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sf := files[bundleSettingsName]
+	if sf == nil {
+		return Settings{}, errors.New("bundle has no " + bundleSettingsName)
+	}
+	data, err := readZipFile(sf)
+	if err != nil {
+		return Settings{}, err
+	}
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return Settings{}, fmt.Errorf("%s: %w", bundleSettingsName, err)
+	}
+	if v := schemaVersionOf(m); v > currentSchemaVersion {
+		return Settings{}, fmt.Errorf("bundle was written by a newer version (schema %d)", v)
+	}
+	if _, err := migrateSettings(m); err != nil {
+		return Settings{}, err
+	}
+	if data, err = json.Marshal(m); err != nil {
+		return Settings{}, err
+	}
+	s := defaultSettings
+	_ = json.Unmarshal(data, &s)
+	_ = openSecrets(&s) // a key sealed on another PC is dropped
+	// Keys locked by the admin policy keep its values, and settings the app
+	// would refuse are rejected before any media is written.
+	activePolicy.apply(&s)
+	if _, err := newTransport(s); err != nil {
+		return Settings{}, fmt.Errorf("network settings: %w", err)
+	}
+
+	// extract copies a bundle entry to dir and reports whether it was there.
+	extract := func(name, dir, fileName string) (bool, error) {
+		f := files[name]
+		if f == nil || !isPlainFileName(fileName) {
+			return false, nil
+		}
+		data, err := readZipFile(f)
+		if err != nil {
+			return false, err
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return false, err
+		}
+		return true, writeFileAtomic(filepath.Join(dir, fileName), data, 0644)
+	}
+
+	backgrounds := []CustomBackground{}
+	for _, bg := range s.CustomBackgrounds {
+		ok, err := extract("backgrounds/"+bg.FileName, backgroundsDir(), bg.FileName)
+		if err != nil {
+			return Settings{}, err
+		}
+		if !ok {
+			clearBackgroundRefs(&s, bg.Id)
+			continue
+		}
+		if bg.ThumbFileName != "" {
+			ok, err := extract("backgrounds/thumbs/"+bg.ThumbFileName, backgroundThumbsDir(), bg.ThumbFileName)
+			if err != nil {
+				return Settings{}, err
+			}
+			if !ok {
+				bg.ThumbFileName = ""
+			}
+		}
+		backgrounds = append(backgrounds, bg)
+	}
+	s.CustomBackgrounds = backgrounds
+
+	var missing []string
+	for _, cs := range s.CustomSounds {
+		ok, err := extract("sounds/"+cs.FileName, soundsDir(), cs.FileName)
+		if err != nil {
+			return Settings{}, err
+		}
+		if !ok {
+			missing = append(missing, cs.Id)
+		}
+	}
+	for _, id := range missing {
+		removeCustomSound(&s, id)
+	}
+
+	if opts.KeepClass {
+		s.Grade, s.ClassNum = local.Grade, local.ClassNum
+	}
+	if opts.KeepAPIKey {
+		s.UseCustomAPIKey, s.CustomAPIKey = local.UseCustomAPIKey, local.CustomAPIKey
+	}
+	s.MuteBellsDate = local.MuteBellsDate
+	// A bundle exported without secrets must not remove this PC's PIN.
+	if s.AdminPINHash == "" {
+		s.AdminPINHash = local.AdminPINHash
+	}
+	return s, nil
+}
+
+func readZipFile(f *zip.File) ([]byte, error) {
+	if f.UncompressedSize64 > bundleMaxFileSize {
+		return nil, fmt.Errorf("%s is too large", f.Name)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, bundleMaxFileSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > bundleMaxFileSize {
+		return nil, fmt.Errorf("%s is too large", f.Name)
+	}
+	return data, nil
+}
+
+// isPlainFileName rejects names that would escape the media folders.
+func isPlainFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, `/\:`)
+}
+
+// pruneReplacedMedia deletes the media files old refers to that replaced no
+// longer does, so an import does not leave the previous backgrounds behind
+// for reconcileBackgrounds to adopt again.
+func pruneReplacedMedia(old, replaced Settings) {
+	keep := make(map[string]bool)
+	for _, bg := range replaced.CustomBackgrounds {
+		keep[filepath.Join(backgroundsDir(), bg.FileName)] = true
+		if bg.ThumbFileName != "" {
+			keep[filepath.Join(backgroundThumbsDir(), bg.ThumbFileName)] = true
+		}
+	}
+	for _, cs := range replaced.CustomSounds {
+		keep[filepath.Join(soundsDir(), cs.FileName)] = true
+	}
+
+	var paths []string
+	for _, bg := range old.CustomBackgrounds {
+		paths = append(paths, filepath.Join(backgroundsDir(), bg.FileName))
+		if bg.ThumbFileName != "" {
+			paths = append(paths, filepath.Join(backgroundThumbsDir(), bg.ThumbFileName))
+		}
+	}
+	for _, cs := range old.CustomSounds {
+		paths = append(paths, filepath.Join(soundsDir(), cs.FileName))
+	}
+	for _, p := range paths {
+		if !keep[p] {
+			os.Remove(p)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func bundleReader(t *testing.T, data []byte) *zip.Reader {
+	t.Helper()
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+	return zr
+}
+
+// --- round trip ---
+
+func TestSettingsBundle_RoundTrip(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	template := defaultSettings
+	template.SchoolName = "템플릿학교"
+	template.Grade, template.ClassNum = 1, 1
+	template.CustomAPIKey = "template-key"
+	template.MuteBellsDate = "20260101"
+	template.BackgroundID = "custom:bg"
+	template.CustomBackgrounds = []CustomBackground{{Id: "bg", FileName: "bg.jpg", ThumbFileName: "bg.jpg"}}
+	template.AlarmSound = "custom:snd"
+	template.CustomSounds = []CustomSound{{Id: "snd", Name: "종", FileName: "snd.wav"}}
+	writeMediaFile(t, backgroundsDir(), "bg.jpg", []byte("image"))
+	writeMediaFile(t, backgroundThumbsDir(), "bg.jpg", []byte("thumb"))
+	writeMediaFile(t, soundsDir(), "snd.wav", []byte("sound"))
+
+	var buf bytes.Buffer
+	if err := writeSettingsBundle(&buf, template, true); err != nil {
+		t.Fatalf("writeSettingsBundle: %v", err)
+	}
+
+	// Import on a fresh PC.
+	_, cleanup2 := overrideSettingsPath(t)
+	defer cleanup2()
+	local := defaultSettings
+	local.Grade, local.ClassNum = 3, 7
+	local.UseCustomAPIKey, local.CustomAPIKey = true, "local-key"
+
+	s, err := readSettingsBundle(bundleReader(t, buf.Bytes()), local, ImportOptions{KeepClass: true, KeepAPIKey: true})
+	if err != nil {
+		t.Fatalf("readSettingsBundle: %v", err)
+	}
+
+	if s.SchoolName != "템플릿학교" || s.BackgroundID != "custom:bg" || s.AlarmSound != "custom:snd" {
+		t.Errorf("template fields not imported: %+v", s)
+	}
+	if s.Grade != 3 || s.ClassNum != 7 || !s.UseCustomAPIKey || s.CustomAPIKey != "local-key" {
+		t.Errorf("local fields not kept: grade %d class %d key %q", s.Grade, s.ClassNum, s.CustomAPIKey)
+	}
+	if s.MuteBellsDate != "" {
+		t.Errorf("MuteBellsDate should not travel: %q", s.MuteBellsDate)
+	}
+	for path, want := range map[string]string{
+		filepath.Join(backgroundsDir(), "bg.jpg"):      "image",
+		filepath.Join(backgroundThumbsDir(), "bg.jpg"): "thumb",
+		filepath.Join(soundsDir(), "snd.wav"):          "sound",
+	} {
+		if data, err := os.ReadFile(path); err != nil || string(data) != want {
+			t.Errorf("%s: got %q, %v", path, data, err)
+		}
+	}
+}
+
+func TestSettingsBundle_WithoutKeepOptions(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	template := defaultSettings
+	template.Grade, template.ClassNum = 1, 2
+	template.CustomAPIKey = "template-key"
+	var buf bytes.Buffer
+	if err := writeSettingsBundle(&buf, template, true); err != nil {
+		t.Fatal(err)
+	}
+
+	local := defaultSettings
+	local.Grade, local.ClassNum, local.CustomAPIKey = 3, 4, "local-key"
+	s, err := readSettingsBundle(bundleReader(t, buf.Bytes()), local, ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Grade != 1 || s.ClassNum != 2 || s.CustomAPIKey != "template-key" {
+		t.Errorf("got grade %d class %d key %q", s.Grade, s.ClassNum, s.CustomAPIKey)
+	}
+}
+
+func TestReadSettingsBundle_KeepsLocalPINWithoutSecrets(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	template := defaultSettings
+	template.AdminPINHash = "template-pin"
+	local := defaultSettings
+	local.AdminPINHash = "local-pin"
+
+	for includeSecrets, want := range map[bool]string{false: "local-pin", true: "template-pin"} {
+		var buf bytes.Buffer
+		if err := writeSettingsBundle(&buf, template, includeSecrets); err != nil {
+			t.Fatal(err)
+		}
+		s, err := readSettingsBundle(bundleReader(t, buf.Bytes()), local, ImportOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s.AdminPINHash != want {
+			t.Errorf("includeSecrets=%v: AdminPINHash = %q, want %q", includeSecrets, s.AdminPINHash, want)
+		}
+	}
+}
+
+// --- missing or unsafe media ---
+
+func TestReadSettingsBundle_DropsMissingMedia(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	template := defaultSettings
+	template.BackgroundID = "custom:gone"
+	template.CustomBackgrounds = []CustomBackground{
+		{Id: "gone", FileName: "gone.jpg"},
+		{Id: "evil", FileName: "../evil.jpg"},
+	}
+	template.AlarmSound = "custom:snd"
+	template.CustomSounds = []CustomSound{{Id: "snd", FileName: "snd.wav"}}
+
+	// Written by hand: the exporter never includes a "../" entry.
+	data, err := json.Marshal(template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := buildZip(t, map[string]string{
+		bundleSettingsName:        string(data),
+		"backgrounds/../evil.jpg": "x",
+	})
+
+	s, err := readSettingsBundle(bundleReader(t, bundle), defaultSettings, ImportOptions{})
+	if err != nil {
+		t.Fatalf("readSettingsBundle: %v", err)
+	}
+	if len(s.CustomBackgrounds) != 0 || s.BackgroundID != "" {
+		t.Errorf("backgrounds: got %+v, BackgroundID %q", s.CustomBackgrounds, s.BackgroundID)
+	}
+	if len(s.CustomSounds) != 0 || s.AlarmSound != defaultSettings.AlarmSound {
+		t.Errorf("sounds: got %+v, AlarmSound %q", s.CustomSounds, s.AlarmSound)
+	}
+	if _, err := os.Stat(filepath.Join(settingsDir, "evil.jpg")); !os.IsNotExist(err) {
+		t.Error("file escaped the backgrounds folder")
+	}
+}
+
+func buildZip(t *testing.T, entries map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range entries {
+		f, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadSettingsBundle_Rejects(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	cases := map[string]map[string]string{
+		"no settings":   {"sounds/a.wav": "x"},
+		"invalid json":  {bundleSettingsName: "{"},
+		"newer version": {bundleSettingsName: `{"schemaVersion": 99}`},
+	}
+	for name, entries := range cases {
+		if _, err := readSettingsBundle(bundleReader(t, buildZip(t, entries)), defaultSettings, ImportOptions{}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReadSettingsBundle_RejectsNetworkSettingsBeforeWritingMedia(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	template := defaultSettings
+	template.CACertFiles = []string{filepath.Join(t.TempDir(), "missing.pem")}
+	template.CustomBackgrounds = []CustomBackground{{Id: "bg", FileName: "bg.jpg"}}
+	data, err := json.Marshal(template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bundle := buildZip(t, map[string]string{
+		bundleSettingsName:   string(data),
+		"backgrounds/bg.jpg": "x",
+	})
+
+	if _, err := readSettingsBundle(bundleReader(t, bundle), defaultSettings, ImportOptions{}); err == nil {
+		t.Fatal("expected an error for a missing CA file")
+	}
+	if _, err := os.Stat(filepath.Join(backgroundsDir(), "bg.jpg")); !os.IsNotExist(err) {
+		t.Error("media written for a rejected bundle")
+	}
+}
+
+func TestReadSettingsBundle_AppliesPolicy(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	p, err := parsePolicy(policyFileName, []byte(`{"schoolCode": "7010057"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	activePolicy = p
+	defer func() { activePolicy = nil }()
+
+	bundle := buildZip(t, map[string]string{bundleSettingsName: `{"schoolCode": "1234567", "grade": 2}`})
+	s, err := readSettingsBundle(bundleReader(t, bundle), defaultSettings, ImportOptions{})
+	if err != nil {
+		t.Fatalf("readSettingsBundle: %v", err)
+	}
+	if s.SchoolCode != "7010057" || s.Grade != 2 {
+		t.Errorf("got schoolCode %q, grade %d", s.SchoolCode, s.Grade)
+	}
+}
+
+// --- pruneReplacedMedia ---
+
+func TestPruneReplacedMedia(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	writeMediaFile(t, backgroundsDir(), "old.jpg", []byte("x"))
+	writeMediaFile(t, backgroundThumbsDir(), "old.jpg", []byte("x"))
+	writeMediaFile(t, backgroundsDir(), "shared.jpg", []byte("x"))
+	writeMediaFile(t, soundsDir(), "old.wav", []byte("x"))
+
+	old := Settings{
+		CustomBackgrounds: []CustomBackground{{Id: "old", FileName: "old.jpg", ThumbFileName: "old.jpg"}, {Id: "shared", FileName: "shared.jpg"}},
+		CustomSounds:      []CustomSound{{Id: "old", FileName: "old.wav"}},
+	}
+	replaced := Settings{CustomBackgrounds: []CustomBackground{{Id: "shared", FileName: "shared.jpg"}}}
+
+	pruneReplacedMedia(old, replaced)
+
+	for _, p := range []string{
+		filepath.Join(backgroundsDir(), "old.jpg"),
+		filepath.Join(backgroundThumbsDir(), "old.jpg"),
+		filepath.Join(soundsDir(), "old.wav"),
+	} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("%s should be deleted", p)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(backgroundsDir(), "shared.jpg")); err != nil {
+		t.Errorf("shared.jpg should be kept: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// currentSchemaVersion is the settings.json layout this build writes.
+//
+//	0: settings of the older Electrobun app (src/bun/index.ts)
+//	1: Wails settings before versioning, custom alarm inline as base64
+//	2: custom alarms stored as files in settingsDir/sounds
+//	3: personal API key sealed with the machine key
+const currentSchemaVersion = 3
+
+// settingsMigration upgrades a raw settings object from version `from` to
+// from+1. Migrations work on the decoded JSON map rather than Settings, so
+// renamed or restructured fields can still be read.
+type settingsMigration struct {
+	from  int
+	name  string
+	apply func(m map[string]any) error
+}
+
+// settingsMigrations must stay ordered by from, one step per version.
+var settingsMigrations = []settingsMigration{
+	{from: 0, name: "import Electrobun settings", apply: migrateElectrobunSettings},
+	{from: 1, name: "move custom alarm to sound files", apply: migrateCustomAlarmSound},
+	{from: 2, name: "seal API key", apply: migrateSealSecrets},
+}
+
+// schemaVersionOf reads the version of a raw settings object. Files written
+// before versioning have none; the Electrobun app is recognised by its
+// alarmPopupEnabled field, which the Wails app never wrote.
+func schemaVersionOf(m map[string]any) int {
+	if v, ok := m["schemaVersion"].(float64); ok {
+		return int(v)
+	}
+	if _, ok := m["alarmPopupEnabled"]; ok {
+		return 0
+	}
+	return 1
+}
+
+// migrateSettings runs every migration newer than the object's version and
+// stamps it with currentSchemaVersion. It returns the version it started from.
+func migrateSettings(m map[string]any) (int, error) {
+	from := schemaVersionOf(m)
+	v := from
+	for _, mig := range settingsMigrations {
+		if mig.from < v {
+			continue
+		}
+		if err := mig.apply(m); err != nil {
+			return from, fmt.Errorf("settings migration %d (%s): %w", mig.from, mig.name, err)
+		}
+		v = mig.from + 1
+	}
+	m["schemaVersion"] = v
+	return from, nil
+}
+
+// migrateElectrobunSettings (schema 0 -> 1) converts the Electrobun app's
+// settings. Both apps share %APPDATA%/Wall-E/settings.json and the same
+// background and alarm preset IDs; the alarm popup has no switch in this app.
+func migrateElectrobunSettings(m map[string]any) error {
+	delete(m, "alarmPopupEnabled")
+	return nil
+}
+
+// upgradeSettingsFile migrates settings.json data written by an older
+// version. The original file is kept as settings.json.v<N>.bak before the
+// upgraded data is written back. Data that is current, newer than this
+// build or not valid JSON is returned unchanged, as is data whose file
+// can't be written.
+// The caller must hold settingsMu.
+func upgradeSettingsFile(data []byte) ([]byte, error) {
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return data, nil
+	}
+	if schemaVersionOf(m) >= currentSchemaVersion {
+		return data, nil
+	}
+	// Migrations may write files (sounds); don't start them if the upgraded
+	// settings can't be saved, e.g. a read-only profile or a locked file.
+	f, err := os.OpenFile(settingsPath, os.O_WRONLY, 0)
+	if err != nil {
+		return data, err
+	}
+	f.Close()
+
+	from, err := migrateSettings(m)
+	if err != nil {
+		return data, err
+	}
+	upgraded, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return data, err
+	}
+
+	backup := fmt.Sprintf("%s.v%d.bak", settingsPath, from)
+	if _, err := os.Stat(backup); os.IsNotExist(err) {
+		if err := os.WriteFile(backup, data, 0644); err != nil {
+			return data, err
+		}
+	}
+	if err := writeFileAtomic(settingsPath, upgraded, 0644); err != nil {
+		return data, err
+	}
+	return upgraded, nil
+}
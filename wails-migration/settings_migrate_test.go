@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"testing"
+)
+
+func writeRawSettings(t *testing.T, dir, raw string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(settingsPath, []byte(raw), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+}
+
+func TestSchemaVersionOf(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want int
+	}{
+		{`{"schoolName":"A","alarmPopupEnabled":true}`, 0},
+		{`{"schoolName":"A"}`, 1},
+		{`{"schemaVersion":2}`, 2},
+	}
+	for _, c := range cases {
+		var m map[string]any
+		json.Unmarshal([]byte(c.raw), &m)
+		if got := schemaVersionOf(m); got != c.want {
+			t.Errorf("%s: got %d, want %d", c.raw, got, c.want)
+		}
+	}
+}
+
+func TestSettingsMigrationsOrdered(t *testing.T) {
+	for i, mig := range settingsMigrations {
+		if mig.from != i {
+			t.Errorf("migration %d (%s) has from=%d", i, mig.name, mig.from)
+		}
+	}
+	if len(settingsMigrations) != currentSchemaVersion {
+		t.Errorf("%d migrations for schema version %d", len(settingsMigrations), currentSchemaVersion)
+	}
+}
+
+func TestLoadSettings_ImportsElectrobunSettings(t *testing.T) {
+	dir, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	electrobun := `{
+  "schoolName": "한빛초등학교",
+  "schoolCode": "7001234",
+  "officeCode": "B10",
+  "grade": 3,
+  "classNum": 2,
+  "latitude": 37.5,
+  "longitude": 127.0,
+  "spreadsheetUrl": "",
+  "alarmEnabled": false,
+  "alarmPopupEnabled": false,
+  "alarmSound": "custom",
+  "customAlarmData": "data:audio/mpeg;base64,SUQz",
+  "customAlarmName": "종소리.mp3",
+  "backgroundId": "cordillera-2026-01-07-00-20-41-utc.jpg"
+}`
+	writeRawSettings(t, dir, electrobun)
+
+	s := loadSettings()
+
+	if s.SchoolName != "한빛초등학교" || s.Grade != 3 || s.AlarmEnabled {
+		t.Errorf("fields not carried over: %+v", s)
+	}
+	if s.BackgroundID != "cordillera-2026-01-07-00-20-41-utc.jpg" {
+		t.Errorf("BackgroundID: got %q", s.BackgroundID)
+	}
+	if len(s.CustomSounds) != 1 || s.AlarmSound != "custom:"+s.CustomSounds[0].Id {
+		t.Errorf("custom alarm not migrated: %q %+v", s.AlarmSound, s.CustomSounds)
+	}
+	if s.SchemaVersion != currentSchemaVersion {
+		t.Errorf("SchemaVersion: got %d", s.SchemaVersion)
+	}
+
+	// The upgraded file is written back and the original kept as a backup.
+	backup, err := os.ReadFile(settingsPath + ".v0.bak")
+	if err != nil || string(backup) != electrobun {
+		t.Errorf("backup: %v", err)
+	}
+	var m map[string]any
+	data, _ := os.ReadFile(settingsPath)
+	json.Unmarshal(data, &m)
+	if _, ok := m["alarmPopupEnabled"]; ok {
+		t.Error("alarmPopupEnabled should be removed from the upgraded file")
+	}
+	if _, ok := m["customAlarmData"]; ok {
+		t.Error("customAlarmData should be removed from the upgraded file")
+	}
+}
+
+func TestLoadSettings_CurrentFileUntouched(t *testing.T) {
+	dir, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	raw := fmt.Sprintf(`{"schemaVersion":%d,"schoolName":"A"}`, currentSchemaVersion)
+	writeRawSettings(t, dir, raw)
+
+	if s := loadSettings(); s.SchoolName != "A" {
+		t.Errorf("SchoolName: got %q", s.SchoolName)
+	}
+	data, _ := os.ReadFile(settingsPath)
+	if string(data) != raw {
+		t.Errorf("current file was rewritten: %s", data)
+	}
+	if _, err := os.Stat(fmt.Sprintf("%s.v%d.bak", settingsPath, currentSchemaVersion)); !os.IsNotExist(err) {
+		t.Error("no backup expected for a current file")
+	}
+}
+
+func TestLoadSettings_UnversionedWailsFile(t *testing.T) {
+	dir, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	writeRawSettings(t, dir, `{"schoolName":"A","alarmSound":"chime","customAlarmData":"","customAlarmName":""}`)
+
+	s := loadSettings()
+
+	if s.SchoolName != "A" || s.AlarmSound != "chime" || s.SchemaVersion != currentSchemaVersion {
+		t.Errorf("got %+v", s)
+	}
+	if _, err := os.Stat(settingsPath + ".v1.bak"); err != nil {
+		t.Errorf("expected a v1 backup: %v", err)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Classroom PCs are often switched off at the wall, so settings.json is
+// written atomically and the previous versions are kept as
+// settings.json.1 (newest) .. settings.json.<settingsBackupCount>.
+const settingsBackupCount = 3
+
+// SettingsStatus reports whether the last load had to repair settings.json.
+type SettingsStatus struct {
+	Recovered   bool      `json:"recovered"`             // restored from BackupFile
+	Reset       bool      `json:"reset"`                 // no usable backup; defaults in use
+	BackupFile  string    `json:"backupFile,omitempty"`  // backup the settings came from
+	CorruptFile string    `json:"corruptFile,omitempty"` // copy of the damaged settings.json
+	Time        time.Time `json:"time"`
+}
+
+var (
+	// settingsStatus is guarded by settingsMu.
+	settingsStatus SettingsStatus
+	// onSettingsRecovered, when set, is called after settings.json was repaired.
+	onSettingsRecovered func(SettingsStatus)
+)
+
+func settingsBackupPath(n int) string {
+	return fmt.Sprintf("%s.%d", settingsPath, n)
+}
+
+// writeFileAtomic writes data to a temp file in the same directory, syncs it
+// and renames it over path, so readers see either the old or the new file.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// writeSettingsFile replaces settings.json with data, first rotating the
+// current file into the backups. A damaged current file is not kept as a
+// backup, and identical data is not rewritten.
+// The caller must hold settingsMu.
+func writeSettingsFile(data []byte) error {
+	current, err := os.ReadFile(settingsPath)
+	if err == nil {
+		if bytes.Equal(current, data) {
+			return nil
+		}
+		if json.Valid(current) {
+			if err := rotateSettingsBackups(current); err != nil {
+				return err
+			}
+		}
+	}
+	return writeFileAtomic(settingsPath, data, 0644)
+}
+
+func rotateSettingsBackups(current []byte) error {
+	for n := settingsBackupCount; n > 1; n-- {
+		if err := os.Rename(settingsBackupPath(n-1), settingsBackupPath(n)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return writeFileAtomic(settingsBackupPath(1), current, 0644)
+}
+
+// recoverSettings handles a settings.json that is not valid JSON: the file
+// is kept as settings.json.corrupt and the newest valid backup is restored.
+// Without one, defaults are written so the app keeps working. Either way the
+// outcome is recorded in settingsStatus for the UI.
+// The caller must hold settingsMu.
+func recoverSettings(damaged []byte) Settings {
+	status := SettingsStatus{Time: time.Now()}
+	corrupt := settingsPath + ".corrupt"
+	if err := os.WriteFile(corrupt, damaged, 0644); err == nil {
+		status.CorruptFile = filepath.Base(corrupt)
+	}
+
+	for n := 1; n <= settingsBackupCount; n++ {
+		data, err := os.ReadFile(settingsBackupPath(n))
+		if err != nil || !json.Valid(data) {
+			continue
+		}
+		if err := writeFileAtomic(settingsPath, data, 0644); err != nil {
+			continue
+		}
+		status.Recovered = true
+		status.BackupFile = filepath.Base(settingsBackupPath(n))
+		reportSettingsRecovery(status)
+		return decodeSettings(data)
+	}
+
+	s := defaultSettings
+	if data, err := json.MarshalIndent(s, "", "  "); err == nil {
+		writeFileAtomic(settingsPath, data, 0644)
+	}
+	status.Reset = true
+	reportSettingsRecovery(status)
+	return s
+}
+
+func reportSettingsRecovery(status SettingsStatus) {
+	settingsStatus = status
+	if hook := onSettingsRecovered; hook != nil {
+		go hook(status)
+	}
+}
+
+// decodeSettings upgrades and unmarshals valid settings JSON onto the
+// defaults and opens its secrets. Fields of the wrong type are skipped,
+// keeping their defaults.
+// The caller must hold settingsMu.
+func decodeSettings(data []byte) Settings {
+	s := defaultSettings
+	if upgraded, err := upgradeSettingsFile(data); err == nil {
+		data = upgraded
+	} else {
+		log.Printf("settings: %v", err)
+	}
+	_ = json.Unmarshal(data, &s)
+	if err := openSecrets(&s); err != nil {
+		log.Printf("settings: secrets cleared: %v", err)
+	}
+	return s
+}
+
+// getSettingsStatus returns the outcome of the last settings repair, if any.
+func getSettingsStatus() SettingsStatus {
+	settingsMu.Lock()
+	defer settingsMu.Unlock()
+	return settingsStatus
+}
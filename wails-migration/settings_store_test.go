@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// --- writeFileAtomic ---
+
+func TestWriteFileAtomic_ReplacesWithoutTempFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "settings.json")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeFileAtomic(path, []byte("new"), 0644); err != nil {
+		t.Fatalf("writeFileAtomic: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "new" {
+		t.Errorf("got %q, %v", data, err)
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("temp file left behind: %v", entries)
+	}
+}
+
+// --- backups ---
+
+func TestSaveSettings_RotatesBackups(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		if err := saveSettings(Settings{SchoolName: name}); err != nil {
+			t.Fatalf("saveSettings(%s): %v", name, err)
+		}
+	}
+
+	// settings.json holds "e"; .1 is the newest backup.
+	want := map[int]string{1: "d", 2: "c", 3: "b"}
+	for n, name := range want {
+		data, err := os.ReadFile(settingsBackupPath(n))
+		if err != nil {
+			t.Fatalf("backup %d: %v", n, err)
+		}
+		if got := decodeSettings(data).SchoolName; got != name {
+			t.Errorf("backup %d: got %q, want %q", n, got, name)
+		}
+	}
+	if _, err := os.Stat(settingsBackupPath(settingsBackupCount + 1)); !os.IsNotExist(err) {
+		t.Errorf("expected at most %d backups", settingsBackupCount)
+	}
+}
+
+func TestSaveSettings_UnchangedSkipsWrite(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	s := Settings{SchoolName: "같은학교"}
+	if err := saveSettings(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := saveSettings(s); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(settingsBackupPath(1)); !os.IsNotExist(err) {
+		t.Error("an identical save should not rotate backups")
+	}
+}
+
+// --- recovery ---
+
+func TestLoadSettings_RecoversFromNewestValidBackup(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	settingsStatus = SettingsStatus{}
+	defer func() { settingsStatus = SettingsStatus{} }()
+
+	for _, name := range []string{"오래된학교", "최근학교", "마지막학교"} {
+		if err := saveSettings(Settings{SchoolName: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Damage settings.json and the newest backup, leaving settings.json.2.
+	if err := os.WriteFile(settingsBackupPath(1), []byte(`{"schoolName": "최`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settingsPath, []byte(`{"schoolName": "마`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	recovered := make(chan SettingsStatus, 1)
+	onSettingsRecovered = func(st SettingsStatus) { recovered <- st }
+	defer func() { onSettingsRecovered = nil }()
+
+	s := loadSettings()
+
+	if s.SchoolName != "오래된학교" {
+		t.Errorf("SchoolName: got %q, want 오래된학교", s.SchoolName)
+	}
+	status := getSettingsStatus()
+	if !status.Recovered || status.Reset || status.BackupFile != "settings.json.2" {
+		t.Errorf("status: got %+v", status)
+	}
+	if data, err := os.ReadFile(settingsPath + ".corrupt"); err != nil || string(data) != `{"schoolName": "마` {
+		t.Errorf("corrupt copy: %q, %v", data, err)
+	}
+	if again := loadSettings(); again.SchoolName != "오래된학교" {
+		t.Errorf("restored file not written back: got %q", again.SchoolName)
+	}
+	select {
+	case st := <-recovered:
+		if !st.Recovered {
+			t.Errorf("hook status: got %+v", st)
+		}
+	case <-time.After(time.Second):
+		t.Error("onSettingsRecovered was not called")
+	}
+}
+
+func TestLoadSettings_ResetsWithoutBackup(t *testing.T) {
+	dir, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+	settingsStatus = SettingsStatus{}
+	defer func() { settingsStatus = SettingsStatus{} }()
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(settingsPath, []byte("\x00\x00\x00"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := loadSettings()
+
+	if s.AlarmSound != defaultSettings.AlarmSound {
+		t.Errorf("AlarmSound: got %q", s.AlarmSound)
+	}
+	if status := getSettingsStatus(); !status.Reset || status.Recovered {
+		t.Errorf("status: got %+v", status)
+	}
+	data, err := os.ReadFile(settingsPath)
+	if err != nil || !json.Valid(data) {
+		t.Errorf("settings.json should hold the defaults: %q, %v", data, err)
+	}
+}
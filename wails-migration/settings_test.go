@@ -2,8 +2,10 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -94,8 +96,7 @@ func TestLoadSettings_EmptyFile(t *testing.T) {
 }
 
 // TestLoadSettings_InvalidJSON verifies graceful handling of corrupt JSON:
-// the struct starts from defaults, unmarshal fails silently, and defaults are
-// returned unchanged.
+// with no backup to restore from, defaults are returned unchanged.
 func TestLoadSettings_InvalidJSON(t *testing.T) {
 	dir, cleanup := overrideSettingsPath(t)
 	defer cleanup()
@@ -194,8 +195,7 @@ func TestSaveLoadRoundTrip(t *testing.T) {
 		SpreadsheetURL:  "https://docs.google.com/spreadsheets/d/example",
 		AlarmEnabled:    true,
 		AlarmSound:      "bell",
-		CustomAlarmData: "base64encodeddata==",
-		CustomAlarmName: "MyAlarm",
+		CustomSounds:    []CustomSound{{Id: "s1", Name: "MyAlarm", FileName: "s1.mp3"}},
 		BackgroundID:    "forest",
 	}
 
@@ -235,11 +235,11 @@ func TestSaveLoadRoundTrip(t *testing.T) {
 	if loaded.AlarmSound != original.AlarmSound {
 		t.Errorf("AlarmSound: got %q, want %q", loaded.AlarmSound, original.AlarmSound)
 	}
-	if loaded.CustomAlarmData != original.CustomAlarmData {
-		t.Errorf("CustomAlarmData: got %q, want %q", loaded.CustomAlarmData, original.CustomAlarmData)
+	if len(loaded.CustomSounds) != 1 || loaded.CustomSounds[0] != original.CustomSounds[0] {
+		t.Errorf("CustomSounds: got %+v, want %+v", loaded.CustomSounds, original.CustomSounds)
 	}
-	if loaded.CustomAlarmName != original.CustomAlarmName {
-		t.Errorf("CustomAlarmName: got %q, want %q", loaded.CustomAlarmName, original.CustomAlarmName)
+	if loaded.SchemaVersion != currentSchemaVersion {
+		t.Errorf("SchemaVersion: got %d, want %d", loaded.SchemaVersion, currentSchemaVersion)
 	}
 	if loaded.BackgroundID != original.BackgroundID {
 		t.Errorf("BackgroundID: got %q, want %q", loaded.BackgroundID, original.BackgroundID)
@@ -262,8 +262,6 @@ func TestSettingsJSONFieldNames(t *testing.T) {
 		SpreadsheetURL:  "D",
 		AlarmEnabled:    true,
 		AlarmSound:      "E",
-		CustomAlarmData: "F",
-		CustomAlarmName: "G",
 		BackgroundID:    "H",
 	}
 
@@ -278,6 +276,7 @@ func TestSettingsJSONFieldNames(t *testing.T) {
 	}
 
 	expectedKeys := []string{
+		"schemaVersion",
 		"schoolName",
 		"schoolCode",
 		"officeCode",
@@ -286,12 +285,27 @@ func TestSettingsJSONFieldNames(t *testing.T) {
 		"latitude",
 		"longitude",
 		"spreadsheetUrl",
+		"useCustomApiKey",
+		"customApiKey",
+		"icsSources",
+		"eventLimit",
+		"eventWindowDays",
 		"alarmEnabled",
 		"alarmSound",
-		"customAlarmData",
-		"customAlarmName",
+		"bellSounds",
+		"customSounds",
+		"bells",
+		"muteBellsDate",
 		"backgroundId",
+		"backgroundRotation",
 		"customBackgrounds",
+		"countdowns",
+		"updateChannel",
+		"proxyUrl",
+		"proxyPacUrl",
+		"noProxy",
+		"caCertFiles",
+		"adminPinHash",
 	}
 
 	for _, key := range expectedKeys {
@@ -408,3 +422,42 @@ func TestSaveSettings_Overwrite(t *testing.T) {
 		t.Errorf("AlarmSound after overwrite: got %q, want %q", loaded.AlarmSound, "bell")
 	}
 }
+
+// --- updateSettings ---
+
+func TestUpdateSettings_ConcurrentUpdatesKeepEveryChange(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := updateSettings(func(s *Settings) bool {
+				s.Countdowns = append(s.Countdowns, Countdown{Id: fmt.Sprint(i), Name: "행사", Date: "20261231"})
+				return true
+			})
+			if err != nil {
+				t.Errorf("updateSettings: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := len(loadSettings().Countdowns); got != 10 {
+		t.Errorf("expected 10 countdowns, got %d", got)
+	}
+}
+
+func TestUpdateSettings_NoChangeSkipsSave(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	if _, err := updateSettings(func(s *Settings) bool { return false }); err != nil {
+		t.Fatalf("updateSettings: %v", err)
+	}
+	if _, err := os.Stat(settingsPath); !os.IsNotExist(err) {
+		t.Errorf("expected no settings file, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// CustomSound is an alarm sound imported by the user. The audio itself lives
+// in settingsDir/sounds so settings.json stays small.
+type CustomSound struct {
+	Id       string `json:"id"`
+	Name     string `json:"name"`
+	FileName string `json:"fileName"`
+}
+
+// BellSounds selects the sound per bell type. Each value is a preset name
+// ("classic", "chime", ...) or "custom:<id>"; empty uses Settings.AlarmSound.
+type BellSounds struct {
+	Warning string `json:"warning"`
+	Start   string `json:"start"`
+	End     string `json:"end"`
+	Extra   string `json:"extra"`
+}
+
+var audioMimeTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".ogg":  "audio/ogg",
+	".m4a":  "audio/mp4",
+	".webm": "audio/webm",
+}
+
+func soundsDir() string {
+	return filepath.Join(settingsDir, "sounds")
+}
+
+func audioMimeType(fileName string) string {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if t := audioMimeTypes[ext]; t != "" {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "audio/mpeg"
+}
+
+// audioExtension maps a MIME type back to a file extension, for sounds that
+// only exist as data URLs.
+func audioExtension(mimeType string) string {
+	for ext, t := range audioMimeTypes {
+		if t == mimeType {
+			return ext
+		}
+	}
+	return ".mp3"
+}
+
+// writeSoundFile stores audio data in the sounds folder under a new ID.
+func writeSoundFile(name, ext string, data []byte) (CustomSound, error) {
+	if err := os.MkdirAll(soundsDir(), 0755); err != nil {
+		return CustomSound{}, err
+	}
+	id := uuid.New().String()
+	fileName := id + strings.ToLower(ext)
+	if err := os.WriteFile(filepath.Join(soundsDir(), fileName), data, 0644); err != nil {
+		return CustomSound{}, err
+	}
+	return CustomSound{Id: id, Name: name, FileName: fileName}, nil
+}
+
+// writeLegacySoundFile stores a migrated sound under an ID derived from its
+// content. A migration that runs again, because the upgraded settings.json
+// couldn't be saved, finds the file already there instead of adding a copy.
+func writeLegacySoundFile(name, ext string, data []byte) (CustomSound, error) {
+	id := contentHash(data)[:32]
+	sound := CustomSound{Id: id, Name: name, FileName: id + strings.ToLower(ext)}
+	path := filepath.Join(soundsDir(), sound.FileName)
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return sound, nil
+	}
+	if err := os.MkdirAll(soundsDir(), 0755); err != nil {
+		return CustomSound{}, err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return CustomSound{}, err
+	}
+	return sound, nil
+}
+
+func containsSoundID(sounds []any, id string) bool {
+	for _, v := range sounds {
+		if cs, ok := v.(map[string]any); ok && cs["id"] == id {
+			return true
+		}
+	}
+	return false
+}
+
+// findCustomSound returns the sound with the given ID.
+func findCustomSound(s Settings, id string) (CustomSound, bool) {
+	for _, cs := range s.CustomSounds {
+		if cs.Id == id {
+			return cs, true
+		}
+	}
+	return CustomSound{}, false
+}
+
+// soundForBell resolves the sound a bell plays: its per-type choice, else the
+// general AlarmSound. Custom sounds that no longer exist fall back to "classic".
+func soundForBell(s Settings, b Bell) string {
+	var sound string
+	switch b.Type {
+	case "warning":
+		sound = s.BellSounds.Warning
+	case "start":
+		sound = s.BellSounds.Start
+	case "end":
+		sound = s.BellSounds.End
+	case "extra":
+		sound = s.BellSounds.Extra
+	}
+	if sound == "" {
+		sound = s.AlarmSound
+	}
+	if id, ok := strings.CutPrefix(sound, "custom:"); ok {
+		if _, found := findCustomSound(s, id); found {
+			return sound
+		}
+		return "classic"
+	}
+	if sound == "" || sound == "custom" {
+		return "classic"
+	}
+	return sound
+}
+
+// removeCustomSound drops the sound from settings, resets any selection that
+// used it and returns the removed entry.
+func removeCustomSound(s *Settings, id string) (CustomSound, bool) {
+	removed, found := findCustomSound(*s, id)
+	if !found {
+		return CustomSound{}, false
+	}
+	remaining := []CustomSound{}
+	for _, cs := range s.CustomSounds {
+		if cs.Id != id {
+			remaining = append(remaining, cs)
+		}
+	}
+	s.CustomSounds = remaining
+
+	ref := "custom:" + id
+	if s.AlarmSound == ref {
+		s.AlarmSound = defaultSettings.AlarmSound
+	}
+	for _, sel := range []*string{&s.BellSounds.Warning, &s.BellSounds.Start, &s.BellSounds.End, &s.BellSounds.Extra} {
+		if *sel == ref {
+			*sel = ""
+		}
+	}
+	return removed, true
+}
+
+// decodeDataURL splits a base64 data URL into its MIME type and payload.
+func decodeDataURL(dataURL string) (string, []byte, error) {
+	rest, ok := strings.CutPrefix(dataURL, "data:")
+	if !ok {
+		return "", nil, errors.New("not a data URL")
+	}
+	meta, payload, ok := strings.Cut(rest, ",")
+	if !ok || !strings.HasSuffix(meta, ";base64") {
+		return "", nil, errors.New("unsupported data URL encoding")
+	}
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSuffix(meta, ";base64"), data, nil
+}
+
+// migrateCustomAlarmSound (schema 1 -> 2) moves the single base64 custom
+// alarm stored in settings.json into the sounds folder and CustomSounds.
+func migrateCustomAlarmSound(m map[string]any) error {
+	dataURL, _ := m["customAlarmData"].(string)
+	name, _ := m["customAlarmName"].(string)
+	delete(m, "customAlarmData")
+	delete(m, "customAlarmName")
+	if dataURL == "" {
+		if m["alarmSound"] == "custom" {
+			m["alarmSound"] = defaultSettings.AlarmSound
+		}
+		return nil
+	}
+
+	mimeType, data, err := decodeDataURL(dataURL)
+	if err != nil {
+		// Unreadable leftovers are dropped rather than carried forever.
+		if m["alarmSound"] == "custom" {
+			m["alarmSound"] = defaultSettings.AlarmSound
+		}
+		return nil
+	}
+
+	if name == "" {
+		name = "사용자 알림음"
+	}
+	sound, err := writeLegacySoundFile(name, audioExtension(mimeType), data)
+	if err != nil {
+		return err
+	}
+	sounds, _ := m["customSounds"].([]any)
+	if !containsSoundID(sounds, sound.Id) {
+		m["customSounds"] = append(sounds, map[string]any{
+			"id":       sound.Id,
+			"name":     sound.Name,
+			"fileName": sound.FileName,
+		})
+	}
+	if m["alarmSound"] == "custom" {
+		m["alarmSound"] = "custom:" + sound.Id
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// --- soundForBell ---
+
+func TestSoundForBell_PerTypeOverridesDefault(t *testing.T) {
+	s := defaultSettings
+	s.AlarmSound = "chime"
+	s.CustomSounds = []CustomSound{{Id: "abc", Name: "종", FileName: "abc.mp3"}}
+	s.BellSounds = BellSounds{End: "custom:abc", Extra: "melody"}
+
+	cases := map[string]string{
+		"warning": "chime",
+		"start":   "chime",
+		"end":     "custom:abc",
+		"extra":   "melody",
+	}
+	for typ, want := range cases {
+		if got := soundForBell(s, Bell{Type: typ}); got != want {
+			t.Errorf("%s: got %q, want %q", typ, got, want)
+		}
+	}
+}
+
+func TestSoundForBell_MissingCustomFallsBackToClassic(t *testing.T) {
+	s := defaultSettings
+	s.AlarmSound = "custom:gone"
+	if got := soundForBell(s, Bell{Type: "start"}); got != "classic" {
+		t.Errorf("got %q, want classic", got)
+	}
+	s.AlarmSound = "custom" // legacy value without a sound ID
+	if got := soundForBell(s, Bell{Type: "start"}); got != "classic" {
+		t.Errorf("got %q, want classic", got)
+	}
+}
+
+// --- removeCustomSound ---
+
+func TestRemoveCustomSound_ResetsSelections(t *testing.T) {
+	s := defaultSettings
+	s.AlarmSound = "custom:a"
+	s.CustomSounds = []CustomSound{{Id: "a", FileName: "a.mp3"}, {Id: "b", FileName: "b.wav"}}
+	s.BellSounds = BellSounds{Start: "custom:a", End: "custom:b"}
+
+	removed, ok := removeCustomSound(&s, "a")
+
+	if !ok || removed.FileName != "a.mp3" {
+		t.Fatalf("got %+v, %v", removed, ok)
+	}
+	if len(s.CustomSounds) != 1 || s.CustomSounds[0].Id != "b" {
+		t.Errorf("CustomSounds: got %+v", s.CustomSounds)
+	}
+	if s.AlarmSound != defaultSettings.AlarmSound || s.BellSounds.Start != "" || s.BellSounds.End != "custom:b" {
+		t.Errorf("selections not reset: %q %+v", s.AlarmSound, s.BellSounds)
+	}
+	if _, ok := removeCustomSound(&s, "missing"); ok {
+		t.Error("expected false for an unknown ID")
+	}
+}
+
+// --- migrateCustomAlarmSound ---
+
+func TestMigrateCustomAlarmSound_MovesToFile(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	m := map[string]any{
+		"alarmSound":      "custom",
+		"customAlarmName": "bell.wav",
+		"customAlarmData": "data:audio/wav;base64,UklGRg==",
+	}
+
+	if err := migrateCustomAlarmSound(m); err != nil {
+		t.Fatalf("migrateCustomAlarmSound: %v", err)
+	}
+	if _, ok := m["customAlarmData"]; ok {
+		t.Error("legacy fields should be removed")
+	}
+	sounds, _ := m["customSounds"].([]any)
+	if len(sounds) != 1 {
+		t.Fatalf("expected one custom sound, got %v", m["customSounds"])
+	}
+	sound := sounds[0].(map[string]any)
+	if sound["name"] != "bell.wav" || filepath.Ext(sound["fileName"].(string)) != ".wav" {
+		t.Errorf("got %v", sound)
+	}
+	if m["alarmSound"] != "custom:"+sound["id"].(string) {
+		t.Errorf("alarmSound: got %v", m["alarmSound"])
+	}
+	data, err := os.ReadFile(filepath.Join(soundsDir(), sound["fileName"].(string)))
+	if err != nil || string(data) != "RIFF" {
+		t.Errorf("sound file: %q, %v", data, err)
+	}
+}
+
+func TestMigrateCustomAlarmSound_RerunReusesFile(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	legacy := func() map[string]any {
+		return map[string]any{
+			"alarmSound":      "custom",
+			"customAlarmData": "data:audio/wav;base64,UklGRg==",
+		}
+	}
+	first, second := legacy(), legacy()
+	if err := migrateCustomAlarmSound(first); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	if err := migrateCustomAlarmSound(second); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+
+	if first["alarmSound"] != second["alarmSound"] {
+		t.Errorf("runs picked different sounds: %v, %v", first["alarmSound"], second["alarmSound"])
+	}
+	if entries, _ := os.ReadDir(soundsDir()); len(entries) != 1 {
+		t.Errorf("expected one sound file, got %d", len(entries))
+	}
+}
+
+func TestUpgradeSettingsFile_UnwritableFileWritesNoSounds(t *testing.T) {
+	_, cleanup := overrideSettingsPath(t)
+	defer cleanup()
+
+	// settings.json doesn't exist, so the upgrade can't be saved.
+	data := []byte(`{"alarmSound": "custom", "customAlarmData": "data:audio/wav;base64,UklGRg=="}`)
+	if got, err := upgradeSettingsFile(data); err == nil || string(got) != string(data) {
+		t.Errorf("expected the data back unchanged with an error, got %s, %v", got, err)
+	}
+	if _, err := os.Stat(soundsDir()); !os.IsNotExist(err) {
+		t.Errorf("no sound file should be written, stat: %v", err)
+	}
+}
+
+func TestMigrateCustomAlarmSound_InvalidDataDropped(t *testing.T) {
+	m := map[string]any{"alarmSound": "custom", "customAlarmData": "not a data url"}
+
+	if err := migrateCustomAlarmSound(m); err != nil {
+		t.Fatalf("migrateCustomAlarmSound: %v", err)
+	}
+	if _, ok := m["customAlarmData"]; ok || m["alarmSound"] != defaultSettings.AlarmSound {
+		t.Errorf("got %v", m)
+	}
+}
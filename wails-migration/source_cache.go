@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Dashboard sources are fetched through a sourceCache, so overlapping
+// FetchDashboardData calls (a settings change racing the refresh timer)
+// share one request per source, and a repeat call within sourceCacheTTL
+// reuses the last result. Entries are keyed by source and inputs, so a
+// settings change misses the cache by itself.
+const sourceCacheTTL = 30 * time.Second
+
+// sourceCacheWaiting, when set, is called each time a caller starts waiting
+// for a fetch in flight, so tests know when every caller has joined.
+var sourceCacheWaiting func(key string)
+
+type sourceCache struct {
+	mu      sync.Mutex
+	entries map[string]*sourceEntry
+}
+
+type sourceEntry struct {
+	done     chan struct{} // closed when the fetch finishes
+	value    any
+	err      error
+	canceled bool // the fetching caller's context ended
+	fetched  time.Time
+}
+
+// cachedFetch returns a fresh cached result for key, waits for a fetch of
+// key already in flight, or runs fn. Only successes are cached. A fetch
+// that was cancelled is not shared with callers that were not.
+func cachedFetch[T any](c *sourceCache, ctx context.Context, key string, fn func(context.Context) (T, error)) (T, error) {
+	for {
+		c.mu.Lock()
+		now := time.Now()
+		e := c.entries[key]
+		if e == nil || e.finished() && (e.err != nil || e.canceled || now.Sub(e.fetched) >= sourceCacheTTL) {
+			e = &sourceEntry{done: make(chan struct{})}
+			c.store(key, e, now)
+			c.mu.Unlock()
+
+			v, err := fn(ctx)
+			c.mu.Lock()
+			e.value, e.err, e.fetched = v, err, time.Now()
+			e.canceled = ctx.Err() != nil
+			close(e.done)
+			c.mu.Unlock()
+			return v, err
+		}
+		c.mu.Unlock()
+		if hook := sourceCacheWaiting; hook != nil {
+			hook(key)
+		}
+
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			var zero T
+			return zero, ctx.Err()
+		}
+		if e.canceled && ctx.Err() == nil {
+			continue // the owner gave up; fetch again for this caller
+		}
+		v, _ := e.value.(T)
+		return v, e.err
+	}
+}
+
+func (e *sourceEntry) finished() bool {
+	select {
+	case <-e.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// store adds e and drops expired entries, so keys of old settings do not
+// pile up. The caller must hold c.mu.
+func (c *sourceCache) store(key string, e *sourceEntry, now time.Time) {
+	if c.entries == nil {
+		c.entries = make(map[string]*sourceEntry)
+	}
+	for k, old := range c.entries {
+		if old.finished() && now.Sub(old.fetched) >= sourceCacheTTL {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = e
+}
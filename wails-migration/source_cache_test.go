@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// watchWaiters returns a channel that receives a value each time a caller
+// starts waiting for a fetch in flight.
+func watchWaiters(t *testing.T) <-chan string {
+	t.Helper()
+	joined := make(chan string, 16)
+	sourceCacheWaiting = func(key string) { joined <- key }
+	t.Cleanup(func() { sourceCacheWaiting = nil })
+	return joined
+}
+
+func TestCachedFetch_CoalescesConcurrentCalls(t *testing.T) {
+	var c sourceCache
+	var calls atomic.Int32
+	joined := watchWaiters(t)
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) (int, error) {
+		calls.Add(1)
+		<-release
+		return 42, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]int, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = cachedFetch(&c, context.Background(), "k", fetch)
+		}()
+	}
+	// One caller fetches; release it only once the other four wait on it.
+	for range len(results) - 1 {
+		<-joined
+	}
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("fetch ran %d times, want 1", calls.Load())
+	}
+	for i, v := range results {
+		if v != 42 {
+			t.Errorf("caller %d got %d", i, v)
+		}
+	}
+}
+
+func TestCachedFetch_TTL(t *testing.T) {
+	var c sourceCache
+	calls := 0
+	fetch := func(ctx context.Context) (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	cachedFetch(&c, context.Background(), "k", fetch)
+	if v, _ := cachedFetch(&c, context.Background(), "k", fetch); v != 1 || calls != 1 {
+		t.Errorf("repeat call got %d after %d fetches, want the cached 1", v, calls)
+	}
+	if v, _ := cachedFetch(&c, context.Background(), "other", fetch); v != 2 {
+		t.Errorf("other key got %d, want a new fetch", v)
+	}
+
+	c.entries["k"].fetched = time.Now().Add(-sourceCacheTTL)
+	if v, _ := cachedFetch(&c, context.Background(), "k", fetch); v != 3 {
+		t.Errorf("expired entry got %d, want a new fetch", v)
+	}
+}
+
+func TestCachedFetch_ErrorsNotCached(t *testing.T) {
+	var c sourceCache
+	calls := 0
+	fetch := func(ctx context.Context) (int, error) {
+		calls++
+		if calls == 1 {
+			return 0, errors.New("boom")
+		}
+		return 7, nil
+	}
+
+	if _, err := cachedFetch(&c, context.Background(), "k", fetch); err == nil {
+		t.Fatal("want the first error")
+	}
+	if v, err := cachedFetch(&c, context.Background(), "k", fetch); err != nil || v != 7 {
+		t.Errorf("got %d, %v; want a retry", v, err)
+	}
+}
+
+func TestCachedFetch_CancelledOwnerNotShared(t *testing.T) {
+	var c sourceCache
+	joined := watchWaiters(t)
+	started := make(chan struct{})
+	var calls atomic.Int32
+	fetch := func(ctx context.Context) (int, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+			<-ctx.Done()
+			return 0, ctx.Err()
+		}
+		return 9, nil
+	}
+
+	ownerCtx, cancel := context.WithCancel(context.Background())
+	go cachedFetch(&c, ownerCtx, "k", fetch)
+	<-started
+
+	done := make(chan int)
+	go func() {
+		v, _ := cachedFetch(&c, context.Background(), "k", fetch)
+		done <- v
+	}()
+	<-joined
+	cancel()
+
+	select {
+	case v := <-done:
+		if v != 9 || calls.Load() != 2 {
+			t.Errorf("waiter got %d after %d fetches, want its own fetch", v, calls.Load())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("waiter stuck after the owner was cancelled")
+	}
+}
+
+func TestCachedFetch_WaiterCancel(t *testing.T) {
+	var c sourceCache
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	go cachedFetch(&c, context.Background(), "k", func(ctx context.Context) (int, error) {
+		close(started)
+		<-release
+		return 1, nil
+	})
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := cachedFetch(&c, ctx, "k", func(ctx context.Context) (int, error) { return 2, nil }); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestSourceCache_PrunesExpired(t *testing.T) {
+	var c sourceCache
+	fetch := func(ctx context.Context) (int, error) { return 1, nil }
+	cachedFetch(&c, context.Background(), "old", fetch)
+	c.entries["old"].fetched = time.Now().Add(-sourceCacheTTL)
+	cachedFetch(&c, context.Background(), "new", fetch)
+	if _, ok := c.entries["old"]; ok {
+		t.Error("expired entry kept")
+	}
+}
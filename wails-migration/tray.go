@@ -19,6 +19,7 @@ func (a *App) setupTray() {
 		mOpen := systray.AddMenuItem("Wall-E 대시보드 열기", "대시보드 열기")
 		systray.AddSeparator()
 		mSettings := systray.AddMenuItem("설정", "설정 열기")
+		mMute := systray.AddMenuItemCheckbox("오늘 종 끄기", "오늘 하루 종소리 끄기 (자정에 해제)", loadSettings().MuteBellsDate == todayStr())
 		systray.AddSeparator()
 		mQuit := systray.AddMenuItem("종료", "앱 종료")
 
@@ -31,11 +32,35 @@ func (a *App) setupTray() {
 					runtime.WindowSetAlwaysOnTop(a.ctx, false)
 				case <-mSettings.ClickedCh:
 					runtime.EventsEmit(a.ctx, "openSettings")
+				case <-mMute.ClickedCh:
+					a.SetBellsMutedToday(!mMute.Checked())
+				case <-a.trayMuteChanged:
+					if muted := a.trayMuted.Load(); muted != mMute.Checked() {
+						if muted {
+							mMute.Check()
+						} else {
+							mMute.Uncheck()
+						}
+					}
 				case <-mQuit.ClickedCh:
-					systray.Quit()
-					runtime.Quit(a.ctx)
+					a.QuitApp()
 				}
 			}
 		}()
 	}, func() {})
 }
+
+func (a *App) quitApp() {
+	systray.Quit()
+	runtime.Quit(a.ctx)
+}
+
+// syncTrayMute updates the tray checkbox to the current manual mute state.
+// Only the tray loop touches the menu item; this hands it the new state.
+func (a *App) syncTrayMute(muted bool) {
+	a.trayMuted.Store(muted)
+	select {
+	case a.trayMuteChanged <- struct{}{}:
+	default:
+	}
+}
@@ -18,22 +18,44 @@ import (
 const (
 	githubRepo = "neohum/wall-e"
 	appVersion = "1.0.14"
+
+	downloadTimeout = 5 * time.Minute
 )
 
+// Update channels, chosen by Settings.UpdateChannel. Empty means stable.
+const (
+	updateChannelStable = "stable" // newest full release
+	updateChannelBeta   = "beta"   // newest release including pre-releases
+	updateChannelOff    = "off"    // no update checks, e.g. pinned by an admin policy
+)
+
+const updatesDisabledMessage = "업데이트가 비활성화되어 있습니다"
+
 type githubRelease struct {
-	TagName string `json:"tag_name"`
-	HTMLURL string `json:"html_url"`
-	Assets  []struct {
+	TagName    string `json:"tag_name"`
+	HTMLURL    string `json:"html_url"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	Assets     []struct {
 		Name               string `json:"name"`
 		BrowserDownloadURL string `json:"browser_download_url"`
 	} `json:"assets"`
 }
 
-func checkForUpdate(currentVersion string) UpdateCheckResult {
+func checkForUpdate(ctx context.Context, currentVersion, channel string) UpdateCheckResult {
+	if channel == updateChannelOff {
+		return UpdateCheckResult{
+			CurrentVersion: currentVersion,
+			Error:          updatesDisabledMessage,
+		}
+	}
+
 	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", githubRepo)
+	if channel == updateChannelBeta {
+		url = fmt.Sprintf("https://api.github.com/repos/%s/releases?per_page=10", githubRepo)
+	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	resp, err := httpGet(ctx, url, nil)
 	if err != nil {
 		return UpdateCheckResult{
 			CurrentVersion: currentVersion,
@@ -50,7 +72,25 @@ func checkForUpdate(currentVersion string) UpdateCheckResult {
 	}
 
 	var release githubRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+	if channel == updateChannelBeta {
+		// The list is newest first and includes pre-releases.
+		var releases []githubRelease
+		if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+			return UpdateCheckResult{
+				CurrentVersion: currentVersion,
+				Error:          "응답 파싱 오류",
+			}
+		}
+		for _, r := range releases {
+			if !r.Draft {
+				release = r
+				break
+			}
+		}
+		if release.TagName == "" {
+			return UpdateCheckResult{CurrentVersion: currentVersion, LatestVersion: currentVersion}
+		}
+	} else if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
 		return UpdateCheckResult{
 			CurrentVersion: currentVersion,
 			Error:          "응답 파싱 오류",
@@ -89,8 +129,15 @@ func downloadAndRunUpdate(ctx context.Context, downloadURL string) string {
 		return "다운로드 URL이 없습니다"
 	}
 
-	client := &http.Client{Timeout: 5 * time.Minute}
-	resp, err := client.Get(downloadURL)
+	// The download streams to disk, so it is not retried and gets a longer
+	// deadline than httpGet's.
+	reqCtx, cancel := context.WithTimeout(ctx, downloadTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return "다운로드 실패: " + err.Error()
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return "다운로드 실패: " + err.Error()
 	}